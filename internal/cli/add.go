@@ -0,0 +1,245 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/cli/safeexec"
+	"github.com/eikster-dk/gh-worktree/internal/worktree"
+	"github.com/spf13/cobra"
+)
+
+func NewAdd() *cobra.Command {
+	var path string
+	var appendBranch bool
+	var name string
+	var copyPatterns []string
+	var copyConfig []string
+	var symlink bool
+	var base string
+	var fetch bool
+	var open bool
+	var editor string
+	var openDryRun bool
+	var layout string
+	var slugify bool
+	var basePath string
+	var dryRun bool
+	var detach bool
+
+	cmd := &cobra.Command{
+		Use:   "add <branch>",
+		Short: "Create a worktree for a branch",
+		Long: `Creates a worktree for an existing branch. With --base, a branch that does not
+exist yet is created from the given ref.
+
+Without --path the worktree is placed according to --layout, a Go template that
+defaults to {{.branch}}. It can use {{.branch}}, {{.repo}}, {{.owner}} and {{.pr}}
+(the PR number in the branch name, if any) and the slug function, which turns
+a branch like feature/x into feature-x. Relative paths are resolved against the
+directory containing the main worktree, or against --base-path (or the
+base_path config option) when set. A leading ~ is expanded, and environment
+variables are expanded in --base-path.
+
+--detach checks out a commit SHA, tag or branch with a detached HEAD
+instead of a branch, e.g. to inspect a release tag. The directory is named
+after the ref unless --name is given.
+
+--name names the directory differently from the branch, e.g. review-1234 for
+a colleague's branch colleague/fix. It replaces the branch in the default
+layout, the last element of a custom --layout, or is appended to --path. It
+must be a single directory name unless it starts with ./ or ../.
+
+--copy-config (or the copy_config config option) copies the values of git
+config keys, e.g. core.hooksPath or user.email, from the current worktree
+into the new one. Only values the new worktree does not already share are
+written, to its worktree-specific config; that needs
+'git config extensions.worktreeConfig true'. Nothing is copied by default.
+
+--dry-run prints the plan instead: the git command creating the worktree, the
+files --copy would bring over, the git config --copy-config would set and the
+editor command --open would run.`,
+		Example: `gh worktree add feature-x --path ../feature-x
+gh worktree add new-feature --base main
+gh worktree add colleague/fix --name review-1234
+gh worktree add v1.2.0 --detach
+gh worktree add feature-x --base-path '~/worktrees/$REPO'`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(args) < 1 {
+				return errors.New("the branch name is required")
+			}
+
+			return nil
+		},
+		ValidArgsFunction: completeAllBranches,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if path != "" && layout != "" {
+				return errors.New("--path and --layout cannot be used together")
+			}
+			if path != "" && basePath != "" {
+				return errors.New("--path and --base-path cannot be used together")
+			}
+			if detach && base != "" {
+				return errors.New("--detach and --base cannot be used together")
+			}
+			if name != "" && (appendBranch || slugify) {
+				return errors.New("--name cannot be used with --append-branch or --slugify")
+			}
+
+			opts := worktree.Options{
+				Path:         path,
+				AppendBranch: appendBranch,
+				Name:         name,
+				Slugify:      slugify,
+				CopyPatterns: copyPatterns,
+				CopyConfig:   copyConfig,
+				Symlink:      symlink,
+				Base:         base,
+				Fetch:        fetch && !detach,
+				Detach:       detach,
+				Progress:     os.Stderr,
+			}
+			if !cmd.Flags().Changed("copy-config") {
+				opts.CopyConfig = loadConfig(cmd.Context()).CopyConfig
+			}
+			if path == "" {
+				cfg := loadConfig(cmd.Context())
+				if layout == "" {
+					layout = cfg.Layout
+				}
+				opts.Layout = layout
+				opts.BaseDir = cfg.BasePath
+				if basePath != "" {
+					opts.BaseDir = basePath
+				}
+				if layout != "" {
+					opts.LayoutVars = layoutVars(cmd.Context(), worktree.PRNumber(args[0]))
+				}
+			}
+
+			// --open-dry-run is a deprecated alias of --dry-run
+			if openDryRun {
+				dryRun = true
+			}
+			if dryRun {
+				plan, err := worktree.PlanAdd(cmd.Context(), args[0], opts)
+				if err != nil {
+					return err
+				}
+				printAddPlan(plan)
+				if open && (editor != "" || os.Getenv("EDITOR") != "") {
+					fmt.Print("Would open: ")
+				}
+				if open {
+					return openInEditor(editor, plan.Path, true)
+				}
+				return nil
+			}
+
+			worktreePath, err := worktree.AddWithOptions(cmd.Context(), args[0], opts)
+			if err != nil {
+				return err
+			}
+
+			fmt.Println(worktreePath)
+
+			if open {
+				return openInEditor(editor, worktreePath, false)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&path, "path", "", "Path to create the worktree at (defaults to a directory named after the branch next to the main worktree)")
+	cmd.Flags().StringVar(&layout, "layout", "", "Template for the worktree path, e.g. '~/worktrees/{{.repo}}/{{slug .branch}}' (see gh worktree add --help)")
+	cmd.Flags().StringVar(&basePath, "base-path", "", "Directory to create new worktrees in, combined with the branch or --layout path (defaults to the directory containing the main worktree)")
+	cmd.Flags().StringVar(&name, "name", "", "Name the worktree directory this instead of after the branch")
+	cmd.Flags().BoolVar(&appendBranch, "append-branch", false, "Append branch name as subdirectory to the provided path")
+	cmd.Flags().BoolVar(&slugify, "slugify", false, "Name the directory feature-x instead of nesting feature/x for branches with slashes")
+	cmd.Flags().StringVar(&base, "base", "", "Create the branch from this ref if it does not exist yet")
+	cmd.Flags().BoolVar(&detach, "detach", false, "Check out a commit SHA, tag or branch with a detached HEAD instead of a branch")
+	cmd.Flags().BoolVar(&fetch, "fetch", true, "Fetch the branch from origin when it only exists on the remote")
+	cmd.Flags().BoolVar(&open, "open", false, "Open the new worktree in your editor")
+	cmd.Flags().StringVar(&editor, "editor", "", "Editor command used by --open (defaults to $EDITOR)")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print what would be done without creating the worktree")
+	cmd.Flags().BoolVar(&openDryRun, "open-dry-run", false, "Print the editor command used by --open instead of running it")
+	_ = cmd.Flags().MarkDeprecated("open-dry-run", "use --dry-run instead, which also prints the editor command")
+	cmd.Flags().StringArrayVar(&copyPatterns, "copy", nil, "Glob pattern, relative to the current worktree root, of files to copy into the new worktree (repeatable)")
+	cmd.Flags().StringArrayVar(&copyConfig, "copy-config", nil, "Git config key, e.g. user.email, to copy from the current worktree into the new one (repeatable)")
+	cmd.Flags().BoolVar(&symlink, "symlink", false, "Symlink the files matched by --copy instead of copying them")
+
+	return cmd
+}
+
+// printAddPlan prints what creating a worktree as planned would do.
+func printAddPlan(plan worktree.AddPlan) {
+	fmt.Printf("Would create worktree at %s\n", plan.Path)
+	fmt.Printf("Would run: %s\n", formatCommand(plan.GitArgs))
+	if plan.MayFetch {
+		fmt.Println("  (fetching the branch from origin first if git can't find it)")
+	}
+
+	verb := "copy"
+	if plan.Symlink {
+		verb = "link"
+	}
+	for _, f := range plan.Files {
+		fmt.Printf("Would %s %s to %s\n", verb, f.Source, f.Target)
+	}
+	for _, c := range plan.Config {
+		fmt.Printf("Would copy git config %s = %s\n", c.Key, c.Value)
+	}
+}
+
+// layoutVars returns the --layout variables besides the branch. The repo and
+// owner come from the current GitHub repository, falling back to the name of
+// the repository directory when it has none.
+func layoutVars(ctx context.Context, pr int) map[string]interface{} {
+	vars := map[string]interface{}{"repo": "", "owner": "", "pr": ""}
+	if pr > 0 {
+		vars["pr"] = pr
+	}
+	if repo, err := currentRepository(); err == nil {
+		vars["repo"] = repo.Name()
+		vars["owner"] = repo.Owner()
+	} else if root, err := worktree.RepoRoot(ctx); err == nil {
+		if abs, err := worktree.AbsPath(root); err == nil {
+			vars["repo"] = filepath.Base(abs)
+		}
+	}
+	return vars
+}
+
+// openInEditor opens path with editor, falling back to $EDITOR. When no
+// editor is configured a hint is printed instead of failing.
+func openInEditor(editor string, path string, dryRun bool) error {
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		fmt.Fprintf(os.Stderr, "No editor configured. Set $EDITOR or pass --editor to open %s\n", path)
+		return nil
+	}
+
+	args := append(strings.Fields(editor), path)
+	if dryRun {
+		fmt.Println(strings.Join(args, " "))
+		return nil
+	}
+
+	bin, err := safeexec.LookPath(args[0])
+	if err != nil {
+		return fmt.Errorf("could not find editor %q: %w", args[0], err)
+	}
+
+	c := exec.Command(bin, args[1:]...)
+	c.Stdin = os.Stdin
+	c.Stdout = os.Stdout
+	c.Stderr = os.Stderr
+	return c.Run()
+}
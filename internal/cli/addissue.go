@@ -0,0 +1,160 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/cli/go-gh/pkg/repository"
+	"github.com/eikster-dk/gh-worktree/internal/worktree"
+	"github.com/spf13/cobra"
+)
+
+func NewAddIssue() *cobra.Command {
+	var layout string
+
+	cmd := &cobra.Command{
+		Use:   "add-issue <number>",
+		Short: "Create a worktree for the branch linked to an issue",
+		Long: `Looks up the branch linked to the issue, as created with "Create a branch" on
+the issue page or gh issue develop, and creates a worktree for it like add does.
+The branch is fetched from origin when it does not exist locally. When several
+branches are linked you are asked to choose one, which needs a terminal.`,
+		Example: "gh worktree add-issue 42",
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(args) < 1 {
+				return errors.New("the issue number is required")
+			}
+
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			number, err := strconv.Atoi(strings.TrimPrefix(args[0], "#"))
+			if err != nil {
+				return fmt.Errorf("invalid issue number %q", args[0])
+			}
+
+			repo, err := currentRepository()
+			if err != nil {
+				return fmt.Errorf("could not get current repository: %w", err)
+			}
+
+			branches, err := getIssueBranches(cmd.Context(), repo, number)
+			if err != nil {
+				return err
+			}
+			if len(branches) == 0 {
+				return fmt.Errorf("issue #%d has no linked branch in %s/%s, create one with gh issue develop %d", number, repo.Owner(), repo.Name(), number)
+			}
+
+			branch := branches[0]
+			if len(branches) > 1 {
+				if branch, err = chooseBranch(os.Stderr, branches); err != nil {
+					return err
+				}
+			}
+
+			cfg := loadConfig(cmd.Context())
+			if layout == "" {
+				layout = cfg.Layout
+			}
+			worktreePath, err := worktree.AddWithOptions(cmd.Context(), branch, worktree.Options{
+				Layout:     layout,
+				LayoutVars: layoutVars(cmd.Context(), worktree.PRNumber(branch)),
+				BaseDir:    cfg.BasePath,
+				Fetch:      true,
+				Progress:   os.Stderr,
+			})
+			if err != nil {
+				return err
+			}
+
+			fmt.Println(worktreePath)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&layout, "layout", "", "Template for the worktree path, e.g. '{{slug .branch}}' (see gh worktree add --help)")
+
+	return cmd
+}
+
+// getIssueBranches returns the names of the branches in repo linked to
+// issue number. Branches linked in other repositories, such as forks, are
+// left out since they can't be checked out from origin.
+func getIssueBranches(ctx context.Context, repo repository.Repository, number int) ([]string, error) {
+	client, err := gqlClient(repo)
+	if err != nil {
+		return nil, fmt.Errorf("could not get gh graphql client: %w", err)
+	}
+
+	query := `query($owner: String!, $name: String!, $number: Int!) {
+  repository(owner: $owner, name: $name) {
+    issue(number: $number) {
+      linkedBranches(first: 25) {
+        nodes { ref { name repository { nameWithOwner } } }
+      }
+    }
+  }
+}`
+	var resp struct {
+		Repository struct {
+			Issue *struct {
+				LinkedBranches struct {
+					Nodes []struct {
+						Ref *struct {
+							Name       string
+							Repository struct {
+								NameWithOwner string
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+	variables := map[string]interface{}{"owner": repo.Owner(), "name": repo.Name(), "number": number}
+	err = withRetry(ctx, func() error {
+		explainf("+ POST graphql: branches linked to %s/%s#%d", repo.Owner(), repo.Name(), number)
+		return client.DoWithContext(ctx, query, variables, &resp)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not get the branches linked to issue #%d: %w", number, err)
+	}
+	if resp.Repository.Issue == nil {
+		return nil, fmt.Errorf("issue #%d not found in %s/%s", number, repo.Owner(), repo.Name())
+	}
+
+	var branches []string
+	for _, node := range resp.Repository.Issue.LinkedBranches.Nodes {
+		if node.Ref == nil || !strings.EqualFold(node.Ref.Repository.NameWithOwner, repo.Owner()+"/"+repo.Name()) {
+			continue
+		}
+		branches = append(branches, node.Ref.Name)
+	}
+	return branches, nil
+}
+
+// chooseBranch asks on w which of branches to use. Unlike confirm there is
+// no safe default, so it fails when stdin is not a terminal.
+func chooseBranch(w io.Writer, branches []string) (string, error) {
+	if !stdinIsTerminal() {
+		return "", fmt.Errorf("several branches are linked to this issue (%s) and stdin is not a terminal to choose one, use gh worktree add <branch> instead", strings.Join(branches, ", "))
+	}
+
+	fmt.Fprintln(w, "Several branches are linked to this issue:")
+	for i, branch := range branches {
+		fmt.Fprintf(w, "  %d. %s\n", i+1, branch)
+	}
+	fmt.Fprintf(w, "\nWhich one? Enter a number: ")
+	response, _ := stdin.ReadString('\n')
+	idx, err := strconv.Atoi(strings.TrimSpace(response))
+	if err != nil || idx < 1 || idx > len(branches) {
+		return "", errors.New("no branch chosen")
+	}
+	return branches[idx-1], nil
+}
@@ -0,0 +1,161 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/eikster-dk/gh-worktree/internal/worktree"
+	"github.com/spf13/cobra"
+)
+
+// headRepository is the repository a PR head lives in.
+type headRepository struct {
+	FullName string `json:"full_name"`
+	Owner    struct {
+		Login string
+	}
+}
+
+type pullRequest struct {
+	Number int
+	Head   struct {
+		Ref string
+		// Repo is nil when the head repository was deleted
+		Repo *headRepository
+	}
+	Base struct {
+		Repo struct {
+			FullName string `json:"full_name"`
+		}
+	}
+}
+
+// isFork reports whether the PR head lives in a different repository than its base.
+func (pr pullRequest) isFork() bool {
+	return !pr.headRepoDeleted() && pr.Head.Repo.FullName != pr.Base.Repo.FullName
+}
+
+// headRepoDeleted reports whether the repository the PR head lived in is
+// gone, e.g. because the fork was deleted. The head can still be fetched
+// from the base repository then, but there is no owner to name a branch by.
+func (pr pullRequest) headRepoDeleted() bool {
+	return pr.Head.Repo == nil || pr.Head.Repo.FullName == ""
+}
+
+func NewAddPr() *cobra.Command {
+	var layout string
+
+	cmd := &cobra.Command{
+		Use:   "add-pr <number>",
+		Short: "Fetch a PR and create a worktree checked out to its head",
+		Long: `Looks up the head branch of the PR and creates a worktree for it named <number>-<branch>.
+The head is fetched from origin when the branch does not exist locally. PRs from forks are
+fetched into a local branch named <fork-owner>/<branch>, and PRs whose head repository was
+deleted into one named pr-<number>.
+Only the path of the worktree is printed to stdout; progress goes to stderr.`,
+		Example: "gh worktree add-pr 1234",
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(args) < 1 {
+				return errors.New("the pr number is required")
+			}
+
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			number, err := strconv.Atoi(args[0])
+			if err != nil {
+				return err
+			}
+
+			pr, err := getPullRequest(cmd.Context(), number)
+			if err != nil {
+				return err
+			}
+
+			worktreePath, err := addPullRequestWorktree(cmd.Context(), pr, layout)
+			if err != nil {
+				return err
+			}
+
+			fmt.Println(worktreePath)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&layout, "layout", "", "Template for the worktree path, e.g. '{{.pr}}-{{slug .branch}}' (see gh worktree add --help)")
+
+	return cmd
+}
+
+// branch returns the local branch the PR head is checked out to.
+func (pr pullRequest) branch() string {
+	if pr.headRepoDeleted() {
+		return fmt.Sprintf("pr-%d", pr.Number)
+	}
+	if pr.isFork() {
+		return pr.Head.Repo.Owner.Login + "/" + pr.Head.Ref
+	}
+	return pr.Head.Ref
+}
+
+// addPullRequestWorktree fetches the head of pr when its branch does not
+// exist locally and creates a worktree for it, placed by layout or the layout
+// config option when set and named <number>-<branch> otherwise.
+func addPullRequestWorktree(ctx context.Context, pr pullRequest, layout string) (string, error) {
+	branch := pr.branch()
+	if !worktree.BranchExists(ctx, branch) {
+		// Progress goes to stderr: stdout is reserved for the worktree path
+		progress := &output{w: os.Stderr, quiet: quiet}
+		progress.Printf("Fetching PR #%d into %s\n", pr.Number, branch)
+		if err := worktree.Fetch(ctx, "origin", fmt.Sprintf("pull/%d/head:%s", pr.Number, branch)); err != nil {
+			return "", err
+		}
+	}
+
+	cfg := loadConfig(ctx)
+	if layout == "" {
+		layout = cfg.Layout
+	}
+
+	if layout != "" {
+		return worktree.AddWithOptions(ctx, branch, worktree.Options{
+			Layout:     layout,
+			LayoutVars: layoutVars(ctx, pr.Number),
+			BaseDir:    cfg.BasePath,
+		})
+	}
+
+	base, err := worktree.DefaultBaseDir(ctx)
+	if err != nil {
+		return "", fmt.Errorf("could not get working directory: %w", err)
+	}
+	return worktree.Add(ctx, branch, filepath.Join(base, fmt.Sprintf("%d-%s", pr.Number, worktree.Slug(pr.Head.Ref))))
+}
+
+func getPullRequest(ctx context.Context, number int) (pullRequest, error) {
+	repo, err := currentRepository()
+	if err != nil {
+		return pullRequest{}, fmt.Errorf("could not get current repository: %w", err)
+	}
+
+	restApi, err := restClient(repo)
+	if err != nil {
+		return pullRequest{}, fmt.Errorf("could not get gh rest client: %w", err)
+	}
+
+	var pr pullRequest
+	path := fmt.Sprintf("repos/%s/%s/pulls/%d", repo.Owner(), repo.Name(), number)
+	err = withRetry(ctx, func() error {
+		explainf("+ GET %s", path)
+		return restApi.DoWithContext(ctx, "GET", path, nil, &pr)
+	})
+	if err != nil {
+		return pullRequest{}, fmt.Errorf("could not get pull request information: %w", err)
+	}
+
+	return pr, nil
+}
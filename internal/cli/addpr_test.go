@@ -0,0 +1,35 @@
+package cli
+
+import "testing"
+
+func TestPullRequestBranch(t *testing.T) {
+	pr := func(head *headRepository) pullRequest {
+		var pr pullRequest
+		pr.Number = 12
+		pr.Head.Ref = "feature"
+		pr.Head.Repo = head
+		pr.Base.Repo.FullName = "acme/app"
+		return pr
+	}
+	fork := &headRepository{FullName: "octo/app"}
+	fork.Owner.Login = "octo"
+
+	tests := []struct {
+		name string
+		pr   pullRequest
+		want string
+	}{
+		{"same repository", pr(&headRepository{FullName: "acme/app"}), "feature"},
+		{"fork", pr(fork), "octo/feature"},
+		{"deleted head repository", pr(nil), "pr-12"},
+		{"empty head repository", pr(&headRepository{}), "pr-12"},
+	}
+	for _, tt := range tests {
+		if got := tt.pr.branch(); got != tt.want {
+			t.Errorf("%s: branch() = %q, want %q", tt.name, got, tt.want)
+		}
+		if tt.pr.isFork() != (tt.name == "fork") {
+			t.Errorf("%s: isFork() = %v", tt.name, tt.pr.isFork())
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/cli/go-gh/pkg/repository"
+	"github.com/eikster-dk/gh-worktree/internal/worktree"
+	"github.com/spf13/cobra"
+)
+
+// searchPageSize is the number of PRs fetched per page of search results,
+// the most GitHub allows.
+const searchPageSize = 100
+
+func NewAddPrs() *cobra.Command {
+	var author string
+	var limit int
+	var layout string
+
+	cmd := &cobra.Command{
+		Use:   "add-prs --author <login>",
+		Short: "Create worktrees for all open PRs by an author",
+		Long: `Creates a worktree for every open PR by the given author, like add-pr does for one.
+PRs whose branch already has a worktree are skipped. The most recently created
+PRs come first; --limit caps how many are checked out.`,
+		Example: `gh worktree add-prs --author octocat
+gh worktree add-prs --author @me --limit 5`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if author == "" {
+				return errors.New("--author is required")
+			}
+			if limit < 1 {
+				return errors.New("--limit must be at least 1")
+			}
+
+			repo, err := currentRepository()
+			if err != nil {
+				return fmt.Errorf("could not get current repository: %w", err)
+			}
+
+			prs, err := getPullRequestsByAuthor(cmd.Context(), repo, author, limit)
+			if err != nil {
+				return err
+			}
+
+			out := newOutput(false)
+			if len(prs) == 0 {
+				out.Essentialf("No open PRs by %s in %s/%s\n", author, repo.Owner(), repo.Name())
+				return nil
+			}
+
+			var created, skipped, failed int
+			for _, pr := range prs {
+				if path, err := worktree.PathForBranch(cmd.Context(), pr.branch()); err == nil {
+					out.Printf("⏭️  PR #%d (%s) already has a worktree at %s\n", pr.Number, pr.branch(), path)
+					skipped++
+					continue
+				}
+
+				path, err := addPullRequestWorktree(cmd.Context(), pr, layout)
+				if err != nil {
+					out.Essentialf("❌ PR #%d (%s): %v\n", pr.Number, pr.branch(), err)
+					failed++
+					continue
+				}
+				out.Printf("✅ PR #%d (%s) at %s\n", pr.Number, pr.branch(), path)
+				created++
+			}
+
+			out.Essentialf("\n🏁 Created %d, skipped %d, failed %d\n", created, skipped, failed)
+			if failed > 0 {
+				return fmt.Errorf("failed to create %d worktree(s)", failed)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&author, "author", "", "Login of the PR author, or @me for your own PRs")
+	cmd.Flags().IntVar(&limit, "limit", 10, "Maximum number of PRs to check out")
+	cmd.Flags().StringVar(&layout, "layout", "", "Template for the worktree paths, e.g. '{{.pr}}-{{slug .branch}}' (see gh worktree add --help)")
+
+	return cmd
+}
+
+// getPullRequestsByAuthor returns up to limit open PRs by author in repo,
+// newest first. Search results are paged through until limit PRs are found
+// or there are no more.
+func getPullRequestsByAuthor(ctx context.Context, repo repository.Repository, author string, limit int) ([]pullRequest, error) {
+	client, err := gqlClient(repo)
+	if err != nil {
+		return nil, fmt.Errorf("could not get gh graphql client: %w", err)
+	}
+
+	query := `query($q: String!, $first: Int!, $after: String) {
+  search(query: $q, type: ISSUE, first: $first, after: $after) {
+    pageInfo { hasNextPage endCursor }
+    nodes {
+      ... on PullRequest {
+        number
+        headRefName
+        headRepository { nameWithOwner owner { login } }
+        baseRepository { nameWithOwner }
+      }
+    }
+  }
+}`
+	type searchResponse struct {
+		Search struct {
+			PageInfo struct {
+				HasNextPage bool
+				EndCursor   string
+			}
+			Nodes []struct {
+				Number         int
+				HeadRefName    string
+				HeadRepository *struct {
+					NameWithOwner string
+					Owner         struct {
+						Login string
+					}
+				}
+				BaseRepository struct {
+					NameWithOwner string
+				}
+			}
+		}
+	}
+	variables := map[string]interface{}{
+		"q":     fmt.Sprintf("repo:%s/%s is:pr is:open author:%s sort:created-desc", repo.Owner(), repo.Name(), author),
+		"after": nil,
+	}
+
+	var prs []pullRequest
+	for len(prs) < limit {
+		variables["first"] = limit - len(prs)
+		if limit-len(prs) > searchPageSize {
+			variables["first"] = searchPageSize
+		}
+
+		var resp searchResponse
+		explainf("+ POST graphql: open pull requests by %s in %s/%s", author, repo.Owner(), repo.Name())
+		err := withRetry(ctx, func() error {
+			return client.DoWithContext(ctx, query, variables, &resp)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("could not search pull requests: %w", err)
+		}
+
+		for _, node := range resp.Search.Nodes {
+			if node.Number == 0 {
+				continue
+			}
+			var pr pullRequest
+			pr.Number = node.Number
+			pr.Head.Ref = node.HeadRefName
+			pr.Base.Repo.FullName = node.BaseRepository.NameWithOwner
+			// The head repository is gone when the fork was deleted
+			if node.HeadRepository != nil {
+				pr.Head.Repo = &headRepository{FullName: node.HeadRepository.NameWithOwner}
+				pr.Head.Repo.Owner.Login = node.HeadRepository.Owner.Login
+			}
+			prs = append(prs, pr)
+		}
+
+		if !resp.Search.PageInfo.HasNextPage {
+			break
+		}
+		variables["after"] = resp.Search.PageInfo.EndCursor
+	}
+	return prs, nil
+}
@@ -0,0 +1,50 @@
+package cli
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"testing"
+)
+
+// roundTripFunc is an http.RoundTripper standing in for GitHub.
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// fakeAPI routes the API clients of the rest of the test to handle, with
+// gh logged in to github.com and Enterprise hosts.
+func fakeAPI(t *testing.T, handle roundTripFunc) {
+	t.Helper()
+	t.Setenv("GH_CONFIG_DIR", t.TempDir())
+	t.Setenv("GH_TOKEN", "dotcom-token")
+	t.Setenv("GH_ENTERPRISE_TOKEN", "ghe-token")
+
+	savedTransport, savedOffline := apiTransport, offline
+	apiTransport, offline = handle, false
+	authMu.Lock()
+	authByHost = map[string]bool{}
+	authMu.Unlock()
+	t.Cleanup(func() {
+		apiTransport, offline = savedTransport, savedOffline
+		authMu.Lock()
+		authByHost = map[string]bool{}
+		authMu.Unlock()
+	})
+}
+
+// jsonResponse returns a response to req with status and body.
+func jsonResponse(req *http.Request, status int, body string, header http.Header) *http.Response {
+	if header == nil {
+		header = http.Header{}
+	}
+	header.Set("Content-Type", "application/json")
+	return &http.Response{
+		StatusCode: status,
+		Header:     header,
+		Body:       io.NopCloser(bytes.NewBufferString(body)),
+		Request:    req,
+	}
+}
@@ -0,0 +1,159 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/cli/go-gh/pkg/repository"
+)
+
+// terminalStatusTTL is how long merged and closed PR statuses are cached.
+// These states rarely change, so they can live much longer than open ones.
+const terminalStatusTTL = 7 * 24 * time.Hour
+
+type prStatusEntry struct {
+	prInfo
+	Number    int       `json:"number,omitempty"`  // set for branch lookups
+	MovedTo   string    `json:"movedTo,omitempty"` // set for moved repositories
+	FetchedAt time.Time `json:"fetchedAt"`
+}
+
+// prStatusCache is an on-disk cache of PR statuses keyed by host,
+// repository and PR number. It is safe for concurrent use.
+type prStatusCache struct {
+	mu      sync.Mutex
+	path    string
+	ttl     time.Duration
+	entries map[string]prStatusEntry
+}
+
+// loadPRStatusCache reads the cache from the user cache directory. Open PR
+// statuses expire after ttl. A missing or unreadable cache file results in an
+// empty cache.
+func loadPRStatusCache(ttl time.Duration) *prStatusCache {
+	c := &prStatusCache{ttl: ttl, entries: map[string]prStatusEntry{}}
+
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return c
+	}
+	c.path = filepath.Join(dir, "gh-worktree", "pr-status.json")
+
+	b, err := os.ReadFile(c.path)
+	if err != nil {
+		return c
+	}
+	_ = json.Unmarshal(b, &c.entries)
+
+	return c
+}
+
+func prStatusCacheKey(repo repository.Repository, prNumber int) string {
+	return fmt.Sprintf("%s/%s/%s#%d", repo.Host(), repo.Owner(), repo.Name(), prNumber)
+}
+
+func (c *prStatusCache) get(repo repository.Repository, prNumber int) (prInfo, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[prStatusCacheKey(repo, prNumber)]
+	if !ok {
+		return prInfo{}, false
+	}
+
+	ttl := c.ttl
+	if entry.Status == "merged" || entry.Status == "closed" {
+		ttl = terminalStatusTTL
+	}
+	if time.Since(entry.FetchedAt) > ttl {
+		return prInfo{}, false
+	}
+	return entry.prInfo, true
+}
+
+func (c *prStatusCache) set(repo repository.Repository, prNumber int, info prInfo) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[prStatusCacheKey(repo, prNumber)] = prStatusEntry{prInfo: info, FetchedAt: time.Now()}
+}
+
+func prBranchCacheKey(repo repository.Repository, branch string) string {
+	return fmt.Sprintf("%s/%s/%s:%s", repo.Host(), repo.Owner(), repo.Name(), branch)
+}
+
+// getBranch returns the PR found for branch by a previous lookup. A number of
+// 0 means no PR was found; that result expires like an open PR status.
+func (c *prStatusCache) getBranch(repo repository.Repository, branch string) (int, prInfo, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[prBranchCacheKey(repo, branch)]
+	if !ok {
+		return 0, prInfo{}, false
+	}
+
+	ttl := c.ttl
+	if entry.Status == "merged" || entry.Status == "closed" {
+		ttl = terminalStatusTTL
+	}
+	if time.Since(entry.FetchedAt) > ttl {
+		return 0, prInfo{}, false
+	}
+	return entry.Number, entry.prInfo, true
+}
+
+func (c *prStatusCache) setBranch(repo repository.Repository, branch string, number int, info prInfo) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[prBranchCacheKey(repo, branch)] = prStatusEntry{prInfo: info, Number: number, FetchedAt: time.Now()}
+}
+
+func prMovedCacheKey(repo repository.Repository) string {
+	return fmt.Sprintf("%s/%s/%s->", repo.Host(), repo.Owner(), repo.Name())
+}
+
+// getMoved returns the repository repo was found to have been renamed or
+// transferred to. Moves are remembered as long as merged PR statuses.
+func (c *prStatusCache) getMoved(repo repository.Repository) (repository.Repository, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[prMovedCacheKey(repo)]
+	if !ok || time.Since(entry.FetchedAt) > terminalStatusTTL {
+		return nil, false
+	}
+	moved, err := repository.ParseWithHost(entry.MovedTo, repo.Host())
+	if err != nil {
+		return nil, false
+	}
+	return moved, true
+}
+
+func (c *prStatusCache) setMoved(repo repository.Repository, movedTo repository.Repository) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[prMovedCacheKey(repo)] = prStatusEntry{MovedTo: movedTo.Owner() + "/" + movedTo.Name(), FetchedAt: time.Now()}
+}
+
+func (c *prStatusCache) save() error {
+	if c.path == "" {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o755); err != nil {
+		return err
+	}
+	// Replaced atomically so an interrupted or concurrent run never leaves
+	// a truncated cache file behind
+	return writeJSONFile(c.path, c.entries)
+}
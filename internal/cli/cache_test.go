@@ -0,0 +1,49 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cli/go-gh/pkg/repository"
+)
+
+func TestPRStatusCacheHosts(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", dir)
+	t.Setenv("HOME", dir)
+	dotcom, _ := repository.ParseWithHost("acme/app", "github.com")
+	ghe, _ := repository.ParseWithHost("acme/app", "ghe.example.com")
+
+	cache := loadPRStatusCache(time.Hour)
+	cache.set(dotcom, 12, prInfo{Status: "merged"})
+	cache.setBranch(dotcom, "feature", 12, prInfo{Status: "merged"})
+	if err := cache.save(); err != nil {
+		t.Fatalf("save() error = %v", err)
+	}
+
+	cache = loadPRStatusCache(time.Hour)
+	if info, ok := cache.get(dotcom, 12); !ok || info.Status != "merged" {
+		t.Errorf("get(github.com) = %+v, %v, want merged, true", info, ok)
+	}
+	if info, ok := cache.get(ghe, 12); ok {
+		t.Errorf("get(ghe.example.com) = %+v, true, want no entry", info)
+	}
+	if number, _, ok := cache.getBranch(ghe, "feature"); ok {
+		t.Errorf("getBranch(ghe.example.com) = #%d, true, want no entry", number)
+	}
+
+	// Only the cache file is left, no temporary files
+	entries, err := os.ReadDir(filepath.Dir(cache.path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Name() != filepath.Base(cache.path) {
+		var names []string
+		for _, e := range entries {
+			names = append(names, e.Name())
+		}
+		t.Errorf("cache directory contains %v, want only %s", names, filepath.Base(cache.path))
+	}
+}
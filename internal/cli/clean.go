@@ -2,301 +2,1200 @@ package cli
 
 import (
 	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"os"
-	"os/exec"
+	"path"
 	"path/filepath"
-	"regexp"
 	"strconv"
 	"strings"
 	"time"
 
-	gh "github.com/cli/go-gh"
-	"github.com/cli/safeexec"
+	"github.com/eikster-dk/gh-worktree/internal/worktree"
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
 )
 
+// WorktreeInfo is a worktree together with the PR it belongs to.
 type WorktreeInfo struct {
-	Path       string
-	Branch     string
-	PRNumber   int
-	LastCommit time.Time
-	PRStatus   string // "open", "merged", "closed", or ""
+	worktree.Info
+	PRNumber        int    `json:"prNumber"`
+	DaysSinceCommit int    `json:"daysSinceCommit"`
+	PRStatus        string `json:"prStatus"` // "open", "merged", "closed", or ""
+	PRRepo          string `json:"prRepo"`   // OWNER/REPO the PR status was found in
+	PRAuthor        string `json:"prAuthor,omitempty"`
+	Draft           bool   `json:"draft"`
+	Mergeable       string `json:"mergeable,omitempty"`      // "mergeable", "conflicting", "unknown" or ""
+	ReviewDecision  string `json:"reviewDecision,omitempty"` // "approved", "changes_requested", "review_required" or ""
+	Checks          string `json:"checks,omitempty"`         // "success", "failure", "pending", "error", "expected" or ""
+	// UpstreamGone is set by clean --gone when the branch's upstream no
+	// longer exists on its remote
+	UpstreamGone bool `json:"upstreamGone,omitempty"`
+	// MergedIntoDefault is set by list and clean when all commits of the
+	// branch are reachable from the default branch
+	MergedIntoDefault bool `json:"mergedIntoDefault"`
+}
+
+// setPR records the PR found for the worktree in repo.
+func (wt *WorktreeInfo) setPR(repo string, info prInfo) {
+	wt.PRStatus = info.Status
+	wt.Draft = info.Draft
+	wt.Mergeable = info.Mergeable
+	wt.ReviewDecision = info.ReviewDecision
+	wt.Checks = info.Checks
+	wt.PRRepo = repo
+	wt.PRAuthor = info.Author
+}
+
+// displayPRStatus returns the PR status, labelling open drafts as "draft".
+func (wt WorktreeInfo) displayPRStatus() string {
+	if wt.PRStatus == "open" && wt.Draft {
+		return "draft"
+	}
+	return wt.PRStatus
+}
+
+// removalReason describes why clean removes the worktree: its merged or
+// closed PR, its upstream branch being gone or its branch being merged into
+// the default branch.
+func (wt WorktreeInfo) removalReason() string {
+	if wt.PRNumber > 0 && (wt.PRStatus == "merged" || wt.PRStatus == "closed") {
+		return fmt.Sprintf("PR #%d - %s", wt.PRNumber, wt.PRStatus)
+	}
+	if wt.UpstreamGone {
+		return fmt.Sprintf("upstream %s is gone", wt.Upstream)
+	}
+	return "merged into the default branch"
+}
+
+// displayBranch returns the branch name, or the abbreviated HEAD for
+// detached worktrees.
+func (wt WorktreeInfo) displayBranch() string {
+	if wt.Detached {
+		head := wt.Head
+		if len(head) > 7 {
+			head = head[:7]
+		}
+		return "detached " + head
+	}
+	return wt.Branch
+}
+
+// lastActivity describes how long ago the worktree's last commit was, e.g.
+// "3 days ago", or "unknown" when its date could not be read.
+func (wt WorktreeInfo) lastActivity() string {
+	if wt.LastCommit.IsZero() {
+		return "unknown"
+	}
+	return fmt.Sprintf("%d days ago", wt.DaysSinceCommit)
+}
+
+// cleanResult is the document printed by clean when --json is set.
+type cleanResult struct {
+	DryRun         bool           `json:"dryRun"`
+	Removed        []WorktreeInfo `json:"removed"`
+	Skipped        []WorktreeInfo `json:"skipped"`
+	Stale          []WorktreeInfo `json:"stale"`
+	Locked         []WorktreeInfo `json:"locked"`
+	Inaccessible   []WorktreeInfo `json:"inaccessible"`
+	ReclaimedBytes int64          `json:"reclaimedBytes,omitempty"`
+	// Failed are the worktrees whose removal was attempted but failed
+	Failed []WorktreeInfo `json:"failed,omitempty"`
+	// DeletedBranches are the local branches deleted by --delete-branch
+	DeletedBranches []string `json:"deletedBranches,omitempty"`
+}
+
+// add appends the worktrees and totals of another repository's result.
+func (result *cleanResult) add(other cleanResult) {
+	result.Removed = append(result.Removed, other.Removed...)
+	result.Skipped = append(result.Skipped, other.Skipped...)
+	result.Stale = append(result.Stale, other.Stale...)
+	result.Locked = append(result.Locked, other.Locked...)
+	result.Inaccessible = append(result.Inaccessible, other.Inaccessible...)
+	result.Failed = append(result.Failed, other.Failed...)
+	result.DeletedBranches = append(result.DeletedBranches, other.DeletedBranches...)
+	result.ReclaimedBytes += other.ReclaimedBytes
+}
+
+// summaryLine returns the counts clean ends with, e.g. "Removed 2, skipped
+// 1, stale 3, locked 0".
+func (result cleanResult) summaryLine() string {
+	summary := fmt.Sprintf("Removed %d, skipped %d, stale %d, locked %d", len(result.Removed), len(result.Skipped), len(result.Stale), len(result.Locked))
+	if len(result.Failed) > 0 {
+		summary += fmt.Sprintf(", failed %d", len(result.Failed))
+	}
+	if len(result.Inaccessible) > 0 {
+		summary += fmt.Sprintf(", inaccessible %d", len(result.Inaccessible))
+	}
+	if len(result.DeletedBranches) > 0 {
+		summary += fmt.Sprintf(", deleted %d branch(es)", len(result.DeletedBranches))
+	}
+	return summary
+}
+
+// cleanSummary is the document written by clean when --summary-file is set,
+// counts only, for collecting cleanup metrics over time.
+type cleanSummary struct {
+	Time            time.Time `json:"time"`
+	DryRun          bool      `json:"dryRun"`
+	Removed         int       `json:"removed"`
+	Skipped         int       `json:"skipped"`
+	Stale           int       `json:"stale"`
+	Locked          int       `json:"locked"`
+	Inaccessible    int       `json:"inaccessible"`
+	Failed          int       `json:"failed"`
+	DeletedBranches int       `json:"deletedBranches"`
+	ReclaimedBytes  int64     `json:"reclaimedBytes"`
+	DurationSeconds float64   `json:"durationSeconds"`
+}
+
+// summarize counts the worktrees in result for a clean run that started at
+// start.
+func (result cleanResult) summarize(start time.Time) cleanSummary {
+	return cleanSummary{
+		Time:            start,
+		DryRun:          result.DryRun,
+		Removed:         len(result.Removed),
+		Skipped:         len(result.Skipped),
+		Stale:           len(result.Stale),
+		Locked:          len(result.Locked),
+		Inaccessible:    len(result.Inaccessible),
+		Failed:          len(result.Failed),
+		DeletedBranches: len(result.DeletedBranches),
+		ReclaimedBytes:  result.ReclaimedBytes,
+		DurationSeconds: time.Since(start).Seconds(),
+	}
 }
 
 func NewClean() *cobra.Command {
 	var dryRun bool
 	var staleDays int
+	var since string
+	var yes bool
+	var jsonOutput bool
+	var force bool
+	var protect []string
+	var noCache bool
+	var cacheTTL time.Duration
+	var mergedOnly bool
+	var closedOnly bool
+	var reportSize bool
+	var includeOpen bool
+	var staleMetric string
+	var explain bool
+	var skipDrafts bool
+	var limit int
+	var resolvePRs bool
+	var deleteBranch bool
+	var exitCode bool
+	var interactive bool
+	var include []string
+	var exclude []string
+	var summaryFile string
+	var gone bool
+	var mergedIntoDefault bool
+	var repoDirs []string
+	var sortBy string
+	var closedGraceFlag string
+	var groupBy string
 
 	cmd := &cobra.Command{
-		Use:   "clean",
+		Use:   "clean [<branch | pr-number>...]",
 		Short: "Clean up worktrees for merged/closed PRs and identify stale worktrees",
-		Long: `Automatically removes worktrees for merged or closed PRs.
-Lists stale worktrees (no commits in 30+ days) for manual review.`,
-		Example: "gh worktree clean",
-		RunE: func(cmd *cobra.Command, args []string) error {
-			fmt.Println("🔍 Analyzing worktrees...")
+		Long: `Automatically removes worktrees for merged or closed PRs, after confirming the
+plan once unless --yes is given or GH_WORKTREE_YES is set to a true value
+such as 1 (--yes=false overrides it). Neither affects --dry-run.
+Lists stale worktrees (no commits in 30+ days) for manual review.
+Worktrees with an open PR are not considered stale unless --include-open is set.
+Pass branch names or PR numbers to only consider those worktrees.
+The worktree containing the current directory is never removed.
+
+With --repo-dir (repeatable, or the repo_dirs config option) each of the
+given repositories is cleaned in turn, as if clean was run in it, followed by
+a summary across all of them.
+
+With --group-by, the summary also shows the removed and stale worktrees
+grouped by PR author, PR status or branch prefix (the part before the first
+slash), with a count per group. Grouping by author looks up the authors of
+PRs whose status was cached without one.
+
+With --closed-grace, worktrees for PRs closed without merging are only removed
+once their last activity is older than the grace period, e.g. 14d. Worktrees
+whose last activity can't be determined are kept. Combined with --closed-only
+this removes nothing but abandoned PRs past the grace period.
 
-			worktrees, err := getWorktreeInfo()
-			if err != nil {
-				return fmt.Errorf("failed to get worktree info: %w", err)
+Activity is measured with --stale-metric:
+  commit  committer date of the worktree HEAD (default)
+  branch  newest commit not on the default branch, or when the worktree
+          was created if the branch has not diverged yet
+  mtime   newest modification time of the files in the worktree
+
+With --exit-code the exit status tells what happened:
+  0  nothing was removed
+  1  an error stopped clean
+  2  worktrees were removed (or would be, with --dry-run)
+  3  some removals failed`,
+		Example: `gh worktree clean
+gh worktree clean feature-a feature-b 1234
+gh worktree clean --repo-dir ~/src/app --repo-dir ~/src/api`,
+		ValidArgsFunction: completeWorktreeBranchList,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			start := time.Now()
+			out := newOutput(jsonOutput)
+			if explain {
+				dryRun = true
+				setExplain(os.Stderr)
+			}
+			if verbosity >= int(levelDebug) {
+				setExplain(os.Stderr)
+			}
+			if mergedOnly && closedOnly {
+				return fmt.Errorf("--merged-only and --closed-only cannot be used together")
+			}
+			// --yes (or --yes=false) takes precedence over GH_WORKTREE_YES,
+			// which --interactive ignores as it asks by design
+			if !cmd.Flags().Changed("yes") && !interactive {
+				yes = yesFromEnv()
+			}
+			if interactive && (yes || jsonOutput) {
+				return fmt.Errorf("--interactive cannot be used with --yes or --json")
+			}
+			removeStatuses := map[string]bool{"merged": !closedOnly, "closed": !mergedOnly}
+			removeLabel := "merged/closed PRs"
+			if gone || mergedIntoDefault {
+				removeLabel = "merged/closed PRs or finished branches"
 			}
 
-			if len(worktrees) == 0 {
-				fmt.Println("No worktrees found besides main.")
-				return nil
+			if err := validateStaleMetric(staleMetric); err != nil {
+				return err
+			}
+			for _, pattern := range append(append([]string{}, include...), exclude...) {
+				if _, err := path.Match(pattern, ""); err != nil {
+					return fmt.Errorf("invalid pattern %q: %w", pattern, err)
+				}
+			}
+			if limit < 0 {
+				return fmt.Errorf("--limit must not be negative")
+			}
+			if err := sortWorktrees(nil, sortBy); err != nil {
+				return fmt.Errorf("--sort: %w", err)
+			}
+			if groupBy != "" {
+				if _, err := groupWorktrees(nil, groupBy); err != nil {
+					return fmt.Errorf("--group-by: %w", err)
+				}
 			}
 
-			repo, err := gh.CurrentRepository()
-			if err != nil {
-				fmt.Println("⚠️  Could not get current repository - skipping PR status checks")
+			if since != "" && cmd.Flags().Changed("stale-days") {
+				return fmt.Errorf("--since and --stale-days cannot be used together")
+			}
+			var closedGrace time.Duration
+			if closedGraceFlag != "" {
+				if mergedOnly {
+					return fmt.Errorf("--closed-grace cannot be used with --merged-only")
+				}
+				d, err := parseSince(closedGraceFlag)
+				if err != nil {
+					return fmt.Errorf("invalid --closed-grace: %w", err)
+				}
+				closedGrace = d
 			}
 
-			var toRemove []WorktreeInfo
-			var staleWorktrees []WorktreeInfo
+			// With several repositories the groups are shown once, across
+			// all of them
+			groupEach := true
 
-			for _, wt := range worktrees {
-				// Skip main worktree
-				if strings.Contains(wt.Path, "/.git") || wt.Branch == "main" || wt.Branch == "master" {
-					continue
+			// cleanRepo runs the whole analysis and cleanup for the
+			// repository in worktree.Dir
+			cleanRepo := func(args []string) (cleanResult, error) {
+				ctx := cmd.Context()
+				cfg := loadConfig(ctx)
+				// Config values apply to this repository only
+				staleDays := staleDays
+				if !cmd.Flags().Changed("stale-days") && cfg.StaleDays > 0 {
+					staleDays = cfg.StaleDays
 				}
+				staleAfter := time.Duration(staleDays) * 24 * time.Hour
+				if since != "" {
+					d, err := parseSince(since)
+					if err != nil {
+						return cleanResult{}, fmt.Errorf("invalid --since: %w", err)
+					}
+					staleAfter = d
+				}
+				staleCutoff := time.Now().Add(-staleAfter)
+				protect := append(append([]string{}, protect...), cfg.Protect...)
 
-				// Check PR status if we have a PR number
-				if wt.PRNumber > 0 && repo != nil {
-					status, err := getPRStatus(repo, wt.PRNumber)
-					if err == nil {
-						wt.PRStatus = status
-						if status == "merged" || status == "closed" {
-							toRemove = append(toRemove, wt)
-							continue
-						}
+				result := cleanResult{DryRun: dryRun, Removed: []WorktreeInfo{}, Skipped: []WorktreeInfo{}, Stale: []WorktreeInfo{}, Locked: []WorktreeInfo{}, Inaccessible: []WorktreeInfo{}}
+
+				// measure returns the disk usage of a worktree when --report-size is set
+				measure := func(wt WorktreeInfo) int64 {
+					if !reportSize {
+						return 0
 					}
+					size, _ := dirSize(wt.Path)
+					return size
 				}
 
-				// Check for stale worktrees
-				daysSinceCommit := int(time.Since(wt.LastCommit).Hours() / 24)
-				if daysSinceCommit > staleDays {
-					staleWorktrees = append(staleWorktrees, wt)
+				out.Println("🔍 Analyzing worktrees...")
+
+				worktrees, err := getWorktreeInfo(ctx)
+				if err != nil {
+					return result, fmt.Errorf("failed to get worktree info: %w", err)
 				}
-			}
 
-			// Remove merged/closed PR worktrees
-			if len(toRemove) > 0 {
-				fmt.Printf("\n🧹 Found %d worktree(s) for merged/closed PRs:\n\n", len(toRemove))
-				for _, wt := range toRemove {
-					fmt.Printf("  • %s (PR #%d - %s)\n", filepath.Base(wt.Path), wt.PRNumber, wt.PRStatus)
-					if !dryRun {
-						if err := removeWorktree(wt.Path); err != nil {
-							fmt.Printf("    ❌ Failed to remove: %v\n", err)
+				if len(worktrees) == 0 {
+					out.Println("No worktrees found besides main.")
+					return result, nil
+				}
+
+				repos, err := resolveRepositories(ctx)
+				if err != nil {
+					out.Println("⚠️  Could not get current repository - skipping PR status checks")
+				} else if err := checkAPI(repos[0].Host()); errors.Is(err, errNotAuthenticated) {
+					out.Essentialf("⚠️  Not logged in to %s - only cached PR statuses are used. Run `gh auth login` to enable PR status checks\n", repos[0].Host())
+				} else if errors.Is(err, errOffline) {
+					out.Println("📴 Offline - only cached PR statuses are used")
+				}
+
+				if len(args) > 0 {
+					if worktrees, err = selectWorktrees(worktrees, args); err != nil {
+						return result, err
+					}
+				}
+
+				applyStaleMetric(ctx, worktrees, staleMetric)
+
+				var mainBranch string
+				for _, wt := range worktrees {
+					if wt.IsMain {
+						mainBranch = wt.Branch
+					}
+				}
+
+				// deleteMergedBranch deletes the branch of a removed worktree
+				// when --delete-branch is set and its PR was merged
+				deleteMergedBranch := func(wt WorktreeInfo, indent string) {
+					if !deleteBranch || wt.PRStatus != "merged" || wt.Branch == "" || wt.Branch == mainBranch {
+						return
+					}
+					if dryRun {
+						explainf("would run: %s", formatCommand([]string{"branch", "-D", wt.Branch}))
+						result.DeletedBranches = append(result.DeletedBranches, wt.Branch)
+						return
+					}
+					if _, err := gitOutput(ctx, "branch", "-D", wt.Branch); err != nil {
+						out.Essentialf("%s❌ Failed to delete branch %s: %v\n", indent, wt.Branch, err)
+						return
+					}
+					out.Printf("%s✅ Deleted branch %s\n", indent, wt.Branch)
+					result.DeletedBranches = append(result.DeletedBranches, wt.Branch)
+				}
+
+				var candidates []WorktreeInfo
+				for _, wt := range worktrees {
+					name := filepath.Base(wt.Path)
+					// Skip main worktree
+					if wt.IsMain || wt.Bare {
+						logf(levelInfo, "%s: skipped, main worktree", name)
+						continue
+					}
+					// Leave out directories filtered by --include and --exclude
+					if !includeWorktreeDir(name, include, exclude) {
+						logf(levelInfo, "%s: skipped, filtered out by --include/--exclude", name)
+						continue
+					}
+					// Skip protected branches
+					if isProtectedBranch(wt.Branch, append(defaultProtectedBranches, protect...)) {
+						logf(levelInfo, "%s: skipped, branch %s is protected", name, wt.Branch)
+						continue
+					}
+					// Locked worktrees are never touched
+					if wt.Locked {
+						logf(levelInfo, "%s: skipped, locked", name)
+						result.Locked = append(result.Locked, wt)
+						continue
+					}
+					// Worktrees on an unmounted volume can't be inspected, and
+					// removing them would throw away their git metadata
+					if wt.Inaccessible {
+						logf(levelInfo, "%s: skipped, directory is inaccessible", name)
+						result.Inaccessible = append(result.Inaccessible, wt)
+						continue
+					}
+					candidates = append(candidates, wt)
+				}
+
+				// Only consider the oldest worktrees when --limit is set
+				if limit > 0 && len(candidates) > limit {
+					_ = sortWorktrees(candidates, "age")
+					for _, wt := range candidates[limit:] {
+						logf(levelInfo, "%s: skipped, not among the %d oldest (--limit)", filepath.Base(wt.Path), limit)
+					}
+					candidates = candidates[:limit]
+				}
+
+				// Check PR status for all candidates concurrently
+				if repos != nil {
+					var cache *prStatusCache
+					if !noCache {
+						cache = loadPRStatusCache(cacheTTL)
+					}
+					if resolvePRs {
+						resolvePRNumbers(ctx, repos, candidates, cache)
+					}
+					fetchPRStatuses(ctx, repos, candidates, cache, newProgress("Checking PR status", !jsonOutput))
+					if groupBy == "author" {
+						fetchPRAuthors(ctx, repos[0].Host(), candidates, cache)
+					}
+					if err := ctx.Err(); err != nil {
+						return result, err
+					}
+				}
+				if gone {
+					markGoneUpstreams(ctx, candidates)
+				}
+				markMergedIntoDefault(ctx, candidates)
+
+				var toRemove []WorktreeInfo
+				var staleWorktrees []WorktreeInfo
+
+				for _, wt := range candidates {
+					name := filepath.Base(wt.Path)
+					switch {
+					case wt.PRNumber == 0:
+						logf(levelInfo, "%s: no PR number in branch or directory name", name)
+					case wt.PRStatus == "":
+						logf(levelInfo, "%s: PR #%d, status unknown (not found or lookup failed)", name, wt.PRNumber)
+					default:
+						logf(levelInfo, "%s: PR #%d is %s in %s", name, wt.PRNumber, wt.displayPRStatus(), wt.PRRepo)
+					}
+
+					// Closed PRs may be reopened, so with --closed-grace their
+					// worktrees are only removed once inactive for a while
+					if wt.PRStatus == "closed" && removeStatuses["closed"] && closedGrace > 0 && withinClosedGrace(wt, closedGrace) {
+						if wt.LastCommit.IsZero() {
+							logf(levelInfo, "%s: kept, PR is closed and its last activity is unknown (--closed-grace %s)", name, closedGraceFlag)
 						} else {
-							fmt.Printf("    ✅ Removed\n")
+							logf(levelInfo, "%s: kept, PR is closed but had activity within --closed-grace %s", name, closedGraceFlag)
 						}
+						continue
+					}
+					if removeStatuses[wt.PRStatus] {
+						logf(levelInfo, "%s: to be removed, PR is %s", name, wt.PRStatus)
+						toRemove = append(toRemove, wt)
+						continue
+					}
+					if wt.UpstreamGone {
+						logf(levelInfo, "%s: to be removed, upstream %s is gone (--gone)", name, wt.Upstream)
+						toRemove = append(toRemove, wt)
+						continue
+					}
+					if mergedIntoDefault && wt.MergedIntoDefault {
+						logf(levelInfo, "%s: to be removed, branch %s is merged into the default branch (--merged-into-default)", name, wt.Branch)
+						toRemove = append(toRemove, wt)
+						continue
+					}
+
+					// Check for stale worktrees. Worktrees with an open PR are
+					// waiting on review rather than abandoned.
+					if wt.PRStatus == "open" && !includeOpen {
+						logf(levelInfo, "%s: kept, PR is open (see --include-open)", name)
+						continue
+					}
+					if wt.PRStatus == "open" && wt.Draft && skipDrafts {
+						logf(levelInfo, "%s: kept, PR is a draft (--skip-drafts)", name)
+						continue
+					}
+					// A worktree whose last commit date could not be read is
+					// never considered stale
+					if wt.LastCommit.IsZero() {
+						logf(levelInfo, "%s: kept, last activity is unknown", name)
+						continue
+					}
+					stale := wt.LastCommit.Before(staleCutoff)
+					logf(levelInfo, "%s: last activity %s (%d days ago, %s metric), stale after %s: %v", name, wt.LastCommit.Format(time.RFC3339), wt.DaysSinceCommit, staleMetric, formatStaleAfter(staleAfter), stale)
+					if stale {
+						staleWorktrees = append(staleWorktrees, wt)
 					}
 				}
-				if dryRun {
-					fmt.Println("\n(Dry run - no worktrees were removed)")
+
+				// The worktree containing the current directory is never
+				// removed, even with --force, so clean can't pull the
+				// directory out from under the shell it was started from
+				keptCurrent := false
+				if cwd, err := os.Getwd(); err == nil {
+					if current, ok := worktreeContaining(candidates, cwd); ok {
+						var found bool
+						if toRemove, found = dropWorktree(toRemove, current.Path); found {
+							out.Essentialf("⚠️  Kept %s (%s): it contains the current directory\n", filepath.Base(current.Path), current.removalReason())
+							result.Skipped = append(result.Skipped, current)
+							keptCurrent = true
+						}
+						if staleWorktrees, found = dropWorktree(staleWorktrees, current.Path); found {
+							out.Essentialf("⚠️  Kept %s (stale): it contains the current directory\n", filepath.Base(current.Path))
+							keptCurrent = true
+						}
+					}
 				}
-			}
 
-			// Show stale worktrees for review
-			if len(staleWorktrees) > 0 {
-				fmt.Printf("\n📅 Found %d stale worktree(s) (no commits in %d+ days):\n\n", len(staleWorktrees), staleDays)
-				for i, wt := range staleWorktrees {
-					daysSince := int(time.Since(wt.LastCommit).Hours() / 24)
-					fmt.Printf("  %d. %s (%s)\n", i+1, filepath.Base(wt.Path), wt.Branch)
-					fmt.Printf("     Last commit: %d days ago\n", daysSince)
-					if wt.PRNumber > 0 && wt.PRStatus != "" {
-						fmt.Printf("     PR #%d (%s)\n", wt.PRNumber, wt.PRStatus)
+				// Find the removals that would be refused up front, so the plan
+				// can tell how many worktrees will really be removed
+				blocked := map[string]error{}
+				for _, wt := range toRemove {
+					if err := checkRemovable(ctx, wt.Path, force); err != nil {
+						blocked[wt.Path] = err
+					} else if err := checkPushed(ctx, wt.Path, force); err != nil {
+						blocked[wt.Path] = err
 					}
 				}
 
-				if !dryRun {
-					fmt.Print("\nWould you like to remove any of these? Enter numbers separated by spaces (or 'all' for all, Enter to skip): ")
-					reader := bufio.NewReader(os.Stdin)
-					response, _ := reader.ReadString('\n')
-					response = strings.TrimSpace(response)
+				// Confirm the plan once before the automatic removals. With
+				// --interactive every removal is chosen by hand instead.
+				declined := false
+				if len(toRemove) > len(blocked) && !dryRun && !yes && !interactive {
+					prompt := os.Stdout
+					if jsonOutput {
+						prompt = os.Stderr
+					}
+					fmt.Fprint(prompt, plainText(fmt.Sprintf("\n📋 About to remove %d worktree(s) for %s, skip %d with uncommitted or unpushed changes, list %d stale\n", len(toRemove)-len(blocked), removeLabel, len(blocked), len(staleWorktrees))))
+					if !confirm(prompt, "Proceed?") {
+						out.Essentialf("Nothing was removed for %s (pass --yes to remove them without confirming)\n", removeLabel)
+						toRemove = nil
+						declined = true
+					}
+				}
 
-					if response != "" {
-						var toDelete []WorktreeInfo
-						if response == "all" {
-							toDelete = staleWorktrees
+				// Remove merged/closed PR worktrees
+				if len(toRemove) > 0 {
+					out.Printf("\n🧹 Found %d worktree(s) for %s:\n\n", len(toRemove), removeLabel)
+					if interactive && !dryRun {
+						for i, wt := range toRemove {
+							fmt.Printf("  %d. %s (%s)\n", i+1, filepath.Base(wt.Path), wt.removalReason())
+						}
+						toRemove = promptForWorktrees(toRemove)
+						if len(toRemove) > 0 {
+							out.Println()
+						}
+					}
+					for _, wt := range toRemove {
+						out.Printf("  • %s (%s)\n", filepath.Base(wt.Path), wt.removalReason())
+						if err := blocked[wt.Path]; err != nil {
+							out.Essentialf("    ⚠️  Skipped: %v\n", err)
+							result.Skipped = append(result.Skipped, wt)
+							continue
+						}
+						size := measure(wt)
+						if dryRun {
+							explainf("would run: %s", formatCommand(removeWorktreeArgs(wt.Path, force)))
+							result.Removed = append(result.Removed, wt)
+							result.ReclaimedBytes += size
+							deleteMergedBranch(wt, "    ")
 						} else {
-							indices := strings.Fields(response)
-							for _, idxStr := range indices {
-								if idx, err := strconv.Atoi(idxStr); err == nil && idx > 0 && idx <= len(staleWorktrees) {
-									toDelete = append(toDelete, staleWorktrees[idx-1])
-								}
+							if err := removeWorktree(ctx, wt.Path, force); err != nil {
+								out.Essentialf("    ❌ Failed to remove: %v\n", err)
+								result.Failed = append(result.Failed, wt)
+							} else {
+								out.Printf("    ✅ Removed\n")
+								result.Removed = append(result.Removed, wt)
+								result.ReclaimedBytes += size
+								deleteMergedBranch(wt, "    ")
 							}
 						}
+					}
+					if dryRun {
+						out.Println("\n(Dry run - no worktrees were removed)")
+					}
+				}
+
+				// Show stale worktrees for review, oldest first by default so
+				// the numbers in the prompt start with the most likely
+				// candidates. The prompt numbers follow the displayed order.
+				if len(staleWorktrees) > 0 {
+					_ = sortWorktrees(staleWorktrees, sortBy)
+					out.Printf("\n📅 Found %d stale worktree(s) (no commits in %s):\n\n", len(staleWorktrees), formatStaleAfter(staleAfter))
+					result.Stale = staleWorktrees
+					for i, wt := range staleWorktrees {
+						out.Printf("  %d. %s (%s)\n", i+1, filepath.Base(wt.Path), wt.displayBranch())
+						out.Printf("     Last commit: %s\n", wt.lastActivity())
+						if wt.PRStatus == "open" && wt.Draft {
+							out.Printf("     PR #%d (draft - stale but has open draft PR)\n", wt.PRNumber)
+						} else if wt.PRStatus == "open" {
+							out.Printf("     PR #%d (open - stale but has open PR)\n", wt.PRNumber)
+						} else if wt.PRNumber > 0 && wt.PRStatus != "" {
+							out.Printf("     PR #%d (%s)\n", wt.PRNumber, wt.PRStatus)
+						}
+					}
+
+					if !dryRun && (yes || !(jsonOutput || quiet)) {
+						toDelete := staleWorktrees
+						if !yes {
+							toDelete = promptForWorktrees(staleWorktrees)
+						}
 
 						for _, wt := range toDelete {
-							if err := removeWorktree(wt.Path); err != nil {
-								fmt.Printf("❌ Failed to remove %s: %v\n", filepath.Base(wt.Path), err)
+							if err := checkRemovable(ctx, wt.Path, force); err != nil {
+								out.Essentialf("⚠️  Skipped %s: %v\n", filepath.Base(wt.Path), err)
+								result.Skipped = append(result.Skipped, wt)
+								continue
+							}
+							size := measure(wt)
+							if err := removeWorktree(ctx, wt.Path, force); err != nil {
+								out.Essentialf("❌ Failed to remove %s: %v\n", filepath.Base(wt.Path), err)
+								result.Failed = append(result.Failed, wt)
 							} else {
-								fmt.Printf("✅ Removed %s\n", filepath.Base(wt.Path))
+								out.Printf("✅ Removed %s\n", filepath.Base(wt.Path))
+								result.Removed = append(result.Removed, wt)
+								result.ReclaimedBytes += size
+								deleteMergedBranch(wt, "")
 							}
 						}
 					}
 				}
+
+				if len(result.Locked) > 0 {
+					out.Printf("\n🔒 Skipped %d locked worktree(s):\n\n", len(result.Locked))
+					for _, wt := range result.Locked {
+						out.Printf("  • %s (%s)\n", filepath.Base(wt.Path), wt.displayBranch())
+						if wt.LockReason != "" {
+							out.Printf("     Reason: %s\n", wt.LockReason)
+						}
+					}
+				}
+
+				if len(result.Inaccessible) > 0 {
+					out.Printf("\n⚠️  Skipped %d inaccessible worktree(s):\n\n", len(result.Inaccessible))
+					for _, wt := range result.Inaccessible {
+						if wt.Missing {
+							out.Printf("  • %s (%s, directory is missing)\n", wt.Path, wt.displayBranch())
+							continue
+						}
+						out.Printf("  • %s (%s)\n", wt.Path, wt.displayBranch())
+					}
+					out.Println("\n   Reconnect the volume, lock them with 'gh worktree lock', or run 'gh worktree prune' if they were deleted.")
+				}
+
+				if reportSize && len(result.Removed) > 0 {
+					verb := "Reclaimed"
+					if dryRun {
+						verb = "Would reclaim"
+					}
+					out.Essentialf("\n💾 %s %s across %d worktree(s)\n", verb, formatBytes(result.ReclaimedBytes), len(result.Removed))
+				}
+
+				if len(toRemove) == 0 && len(staleWorktrees) == 0 && !declined && !keptCurrent {
+					out.Essentialf("✨ All worktrees are active and up to date!\n")
+				} else {
+					if groupBy != "" && groupEach {
+						printGroups(out, result, groupBy)
+					}
+					out.Essentialf("\n🏁 %s\n", result.summaryLine())
+				}
+				return result, nil
 			}
 
-			if len(toRemove) == 0 && len(staleWorktrees) == 0 {
-				fmt.Println("✨ All worktrees are active and up to date!")
+			// With --repo-dir (or the repo_dirs config option) each
+			// repository is cleaned in turn, in its own section
+			dirs := append([]string{}, repoDirs...)
+			if len(dirs) == 0 {
+				dirs = append(dirs, loadConfig(cmd.Context()).RepoDirs...)
+			}
+			if len(dirs) > 1 && len(args) > 0 {
+				return fmt.Errorf("branch and PR number arguments cannot be used with more than one --repo-dir")
+			}
+			if len(dirs) > 1 && repoOverride != "" {
+				return fmt.Errorf("--repo cannot be used with more than one --repo-dir")
+			}
+			for i, dir := range dirs {
+				abs, err := worktree.AbsPath(dir)
+				if err != nil {
+					return err
+				}
+				if info, err := os.Stat(abs); err != nil || !info.IsDir() {
+					return fmt.Errorf("cannot clean '%s': not a directory", dir)
+				}
+				dirs[i] = abs
+			}
+			groupEach = len(dirs) <= 1
+
+			var result cleanResult
+			var failedRepos int
+			if len(dirs) == 0 {
+				var err error
+				if result, err = cleanRepo(args); err != nil {
+					return err
+				}
+			} else {
+				result = cleanResult{DryRun: dryRun, Removed: []WorktreeInfo{}, Skipped: []WorktreeInfo{}, Stale: []WorktreeInfo{}, Locked: []WorktreeInfo{}, Inaccessible: []WorktreeInfo{}}
+				defer func(dir string) { worktree.Dir = dir }(worktree.Dir)
+				for _, dir := range dirs {
+					worktree.Dir = dir
+					out.Essentialf("\n📂 %s\n", dir)
+					err := usePRNumberPatterns(cmd.Context())
+					var repoResult cleanResult
+					if err == nil {
+						repoResult, err = cleanRepo(args)
+					}
+					if err := cmd.Context().Err(); err != nil {
+						return err
+					}
+					if err != nil {
+						// One broken repository does not stop the others
+						fmt.Fprint(os.Stderr, plainText(fmt.Sprintf("❌ %s: %v\n", dir, err)))
+						failedRepos++
+						continue
+					}
+					result.add(repoResult)
+				}
+				if len(dirs) > 1 {
+					if groupBy != "" {
+						printGroups(out, result, groupBy)
+					}
+					out.Essentialf("\n🏁 Total across %d repositories: %s\n", len(dirs), result.summaryLine())
+				}
 			}
 
+			if summaryFile != "" {
+				if err := writeJSONFile(summaryFile, result.summarize(start)); err != nil {
+					return fmt.Errorf("could not write --summary-file: %w", err)
+				}
+			}
+			if jsonOutput {
+				if err := printJSON(result); err != nil {
+					return err
+				}
+			}
+			if failedRepos > 0 {
+				// The errors were reported with each repository
+				cmd.SilenceUsage = true
+				return fmt.Errorf("clean failed in %d of %d repositories", failedRepos, len(dirs))
+			}
+			if exitCode {
+				return cleanExitError(cmd, result)
+			}
 			return nil
 		},
 	}
 
 	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show what would be cleaned without actually removing")
 	cmd.Flags().IntVar(&staleDays, "stale-days", 30, "Number of days without commits to consider a worktree stale")
+	cmd.Flags().StringVar(&since, "since", "", "Time without commits to consider a worktree stale, e.g. 72h, 10d or 2w (replaces --stale-days)")
+	cmd.Flags().StringVar(&sortBy, "sort", "age", "Order of the stale worktrees: age, branch, pr, or status (merged, closed, open, then no PR, each oldest first)")
+	cmd.Flags().StringVar(&groupBy, "group-by", "", "Also summarize removed and stale worktrees grouped by author, status or prefix")
+	cmd.Flags().StringVar(&staleMetric, "stale-metric", staleMetricCommit, "How worktree activity is measured: commit, branch or mtime")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Remove worktrees for merged/closed PRs and all stale worktrees without prompting (or set GH_WORKTREE_YES=1)")
+	cmd.Flags().BoolVarP(&interactive, "interactive", "i", false, "Also ask which worktrees for merged/closed PRs to remove instead of removing all of them")
+	cmd.Flags().StringArrayVar(&include, "include", nil, "Only consider worktrees whose directory name matches this glob pattern (repeatable)")
+	cmd.Flags().StringArrayVar(&exclude, "exclude", nil, "Never consider worktrees whose directory name matches this glob pattern, even if included (repeatable)")
+	cmd.Flags().StringArrayVar(&protect, "protect", nil, "Branch name or glob pattern to never clean, in addition to main and master (repeatable)")
+	cmd.Flags().BoolVar(&force, "force", false, "Remove worktrees even if they have uncommitted changes")
+	cmd.Flags().StringArrayVar(&repoDirs, "repo-dir", nil, "Clean the repository in this directory; repeat to clean several repositories, each in its own section")
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Print the result as JSON instead of human-readable output")
+	cmd.Flags().StringVar(&summaryFile, "summary-file", "", "Also write counts of removed, skipped and stale worktrees, bytes reclaimed and duration as JSON to this file")
+	cmd.Flags().BoolVar(&exitCode, "exit-code", false, "Exit with 2 when worktrees were removed and 3 when some removals failed")
+	cmd.Flags().BoolVar(&gone, "gone", false, "Also remove worktrees whose upstream branch was deleted from its remote (one git ls-remote per remote)")
+	cmd.Flags().BoolVar(&mergedIntoDefault, "merged-into-default", false, "Also remove worktrees whose branch is fully merged into the default branch, with or without a PR")
+	cmd.Flags().BoolVar(&mergedOnly, "merged-only", false, "Only remove worktrees for merged PRs")
+	cmd.Flags().StringVar(&closedGraceFlag, "closed-grace", "", "Only remove worktrees for closed (unmerged) PRs without activity for this long, e.g. 14d or 2w")
+	cmd.Flags().BoolVar(&closedOnly, "closed-only", false, "Only remove worktrees for closed (unmerged) PRs")
+	cmd.Flags().BoolVar(&includeOpen, "include-open", false, "Also list worktrees with an open PR as stale")
+	cmd.Flags().BoolVar(&reportSize, "report-size", false, "Report the disk space reclaimed by removed worktrees")
+	cmd.Flags().BoolVar(&deleteBranch, "delete-branch", false, "Also delete the local branch of removed worktrees whose PR was merged")
+	cmd.Flags().BoolVar(&resolvePRs, "resolve-prs", false, "Look up the PR of branches whose name has no PR number on GitHub (one API call per branch)")
+	cmd.Flags().IntVar(&limit, "limit", 0, "Only consider the N worktrees with the oldest last commit (0 means no limit)")
+	cmd.Flags().BoolVar(&skipDrafts, "skip-drafts", false, "Never list worktrees with a draft PR as stale, even with --include-open")
+	cmd.Flags().BoolVar(&explain, "explain", false, "Like --dry-run, but also print every git command and GitHub API request to stderr")
+	cmd.Flags().CountVarP(&verbosity, "verbose", "v", "Explain on stderr why each worktree is removed, listed or skipped (-vv also prints git commands and API requests)")
+	cmd.Flags().BoolVar(&noCache, "no-cache", false, "Always fetch PR statuses from GitHub instead of the local cache")
+	cmd.Flags().DurationVar(&cacheTTL, "cache-ttl", 5*time.Minute, "How long cached open PR statuses are valid (merged/closed are cached for 7 days)")
 
 	return cmd
 }
 
-func getWorktreeInfo() ([]WorktreeInfo, error) {
-	git, err := safeexec.LookPath("git")
-	if err != nil {
-		return nil, err
+// includeWorktreeDir reports whether a worktree directory named dir passes
+// the --include and --exclude glob patterns. Excludes take precedence, and
+// no includes means every directory is included.
+func includeWorktreeDir(dir string, include []string, exclude []string) bool {
+	for _, pattern := range exclude {
+		if matched, _ := path.Match(pattern, dir); matched {
+			return false
+		}
 	}
-
-	// Get worktree list
-	cmd := exec.Command(git, "worktree", "list", "--porcelain")
-	output, err := cmd.Output()
-	if err != nil {
-		return nil, err
+	if len(include) == 0 {
+		return true
+	}
+	for _, pattern := range include {
+		if matched, _ := path.Match(pattern, dir); matched {
+			return true
+		}
 	}
+	return false
+}
 
-	var worktrees []WorktreeInfo
-	lines := strings.Split(string(output), "\n")
-	var current WorktreeInfo
+// withinClosedGrace reports whether the worktree of a closed PR is kept by
+// --closed-grace: it had activity within grace, or its last activity is
+// unknown because its commit date could not be read.
+func withinClosedGrace(wt WorktreeInfo, grace time.Duration) bool {
+	return wt.LastCommit.IsZero() || !wt.LastCommit.Before(time.Now().Add(-grace))
+}
 
-	for _, line := range lines {
-		if strings.HasPrefix(line, "worktree ") {
-			if current.Path != "" {
-				// Before appending, ensure PR number is extracted
-				if current.PRNumber == 0 {
-					current.PRNumber = extractPRNumber(filepath.Base(current.Path))
-				}
-				worktrees = append(worktrees, current)
-			}
-			current = WorktreeInfo{
-				Path: strings.TrimPrefix(line, "worktree "),
-			}
-		} else if strings.HasPrefix(line, "branch refs/heads/") {
-			current.Branch = strings.TrimPrefix(line, "branch refs/heads/")
-			// Try to extract PR number from branch name
-			current.PRNumber = extractPRNumber(current.Branch)
-			// If no PR number found in branch, try the path
-			if current.PRNumber == 0 {
-				current.PRNumber = extractPRNumber(filepath.Base(current.Path))
-			}
-		} else if line == "" && current.Path != "" {
-			// Before appending, ensure PR number is extracted
-			if current.PRNumber == 0 {
-				current.PRNumber = extractPRNumber(filepath.Base(current.Path))
-			}
-			worktrees = append(worktrees, current)
-			current = WorktreeInfo{}
+// dropWorktree returns worktrees without the one at path, and whether it
+// was there.
+func dropWorktree(worktrees []WorktreeInfo, path string) ([]WorktreeInfo, bool) {
+	var kept []WorktreeInfo
+	for _, wt := range worktrees {
+		if wt.Path != path {
+			kept = append(kept, wt)
 		}
 	}
-	// Handle last worktree if it exists
-	if current.Path != "" {
-		if current.PRNumber == 0 {
-			current.PRNumber = extractPRNumber(filepath.Base(current.Path))
+	return kept, len(kept) < len(worktrees)
+}
+
+// defaultProtectedBranches are never considered for cleaning.
+var defaultProtectedBranches = []string{"main", "master"}
+
+// isProtectedBranch reports whether branch matches any of the given branch
+// names or glob patterns (e.g. "release/*").
+func isProtectedBranch(branch string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if pattern == branch {
+			return true
+		}
+		if matched, err := path.Match(pattern, branch); err == nil && matched {
+			return true
 		}
-		worktrees = append(worktrees, current)
 	}
+	return false
+}
 
-	// Get last commit date for each worktree
-	for i := range worktrees {
-		if worktrees[i].Branch != "" {
-			lastCommit, err := getLastCommitDate(worktrees[i].Path)
-			if err == nil {
-				worktrees[i].LastCommit = lastCommit
-			}
+// promptForWorktrees asks which of the numbered worktrees to remove and
+// returns the chosen ones. An empty answer chooses none, "all" all of them.
+func promptForWorktrees(worktrees []WorktreeInfo) []WorktreeInfo {
+	fmt.Printf("\nWould you like to remove any of these? Enter numbers separated by spaces (or 'all' for all, Enter to skip): ")
+	response, _ := stdin.ReadString('\n')
+	response = strings.TrimSpace(response)
+
+	if response == "all" {
+		return worktrees
+	}
+	var chosen []WorktreeInfo
+	for _, idxStr := range strings.Fields(response) {
+		if idx, err := strconv.Atoi(idxStr); err == nil && idx > 0 && idx <= len(worktrees) {
+			chosen = append(chosen, worktrees[idx-1])
 		}
 	}
+	return chosen
+}
 
-	return worktrees, nil
+// yesFromEnv reports whether the GH_WORKTREE_YES environment variable asks
+// for prompts to be confirmed automatically, as --yes does. It accepts the
+// values of strconv.ParseBool, e.g. 1 or true.
+func yesFromEnv() bool {
+	yes, err := strconv.ParseBool(os.Getenv("GH_WORKTREE_YES"))
+	return err == nil && yes
+}
+
+// stdin reads the answers to all prompts. A reader per prompt would buffer
+// past its line and swallow the answers meant for later prompts.
+var stdin = bufio.NewReader(os.Stdin)
+
+// stdinIsTerminal reports whether stdin is a terminal, i.e. whether anyone
+// can answer a prompt that has no safe default.
+var stdinIsTerminal = func() bool {
+	return term.IsTerminal(int(os.Stdin.Fd()))
+}
+
+// confirm asks question on w and reports whether the user answered yes. No
+// answer, as when stdin is not a terminal, counts as no.
+func confirm(w io.Writer, question string) bool {
+	fmt.Fprintf(w, "%s [y/N] ", question)
+	response, _ := stdin.ReadString('\n')
+	response = strings.ToLower(strings.TrimSpace(response))
+	return response == "y" || response == "yes"
 }
 
-func extractPRNumber(text string) int {
-	// Common patterns: pr-123, pr/123, pull/123, 123-feature, web-frontend-pr-1018
-	// Check most specific patterns first
-	patterns := []string{
-		`[-_]pr[-_/](\d+)`,          // Matches -pr-123, _pr_123, -pr/123
-		`^pr[-_/](\d+)`,             // Matches pr-123, pr_123, pr/123 at start
-		`[-_]pull[-_/](\d+)`,        // Matches -pull-123, _pull_123
-		`^pull[-_/](\d+)`,           // Matches pull-123, pull_123 at start
-		`^(\d+)[-_]`,                // Matches 123-feature at start
-		`[-_](\d{4,})$`,             // Matches feature-1234 at end (4+ digits to avoid false positives)
+// cleanExitError returns the ExitError for result as documented for
+// --exit-code, or nil when nothing was removed.
+func cleanExitError(cmd *cobra.Command, result cleanResult) error {
+	code := cleanExitNothing
+	if len(result.Failed) > 0 {
+		code = cleanExitFailed
+	} else if len(result.Removed) > 0 {
+		code = cleanExitRemoved
+	}
+	if code == cleanExitNothing {
+		return nil
 	}
 
-	for _, pattern := range patterns {
-		re := regexp.MustCompile(pattern)
-		if matches := re.FindStringSubmatch(text); len(matches) > 1 {
-			if num, err := strconv.Atoi(matches[1]); err == nil {
-				return num
+	// The exit code is the result, not a usage error
+	cmd.SilenceUsage = true
+	return ExitError{Code: code}
+}
+
+// selectWorktrees returns the worktrees matching targets, branch names or PR
+// numbers (optionally prefixed with #), in the order of worktrees. Every
+// target must match at least one worktree.
+func selectWorktrees(worktrees []WorktreeInfo, targets []string) ([]WorktreeInfo, error) {
+	matched := map[string]bool{}
+	var selected []WorktreeInfo
+	for _, wt := range worktrees {
+		found := false
+		for _, target := range targets {
+			if wt.Branch == target {
+				found, matched[target] = true, true
+				continue
+			}
+			number, err := strconv.Atoi(strings.TrimPrefix(target, "#"))
+			if err == nil && wt.PRNumber > 0 && wt.PRNumber == number {
+				found, matched[target] = true, true
 			}
 		}
+		if found {
+			selected = append(selected, wt)
+		}
 	}
-	return 0
+
+	for _, target := range targets {
+		if !matched[target] {
+			return nil, fmt.Errorf("no worktree found for '%s'", target)
+		}
+	}
+	return selected, nil
 }
 
-func getLastCommitDate(worktreePath string) (time.Time, error) {
-	git, err := safeexec.LookPath("git")
+func printJSON(v interface{}) error {
+	return writeJSON(os.Stdout, v)
+}
+
+func writeJSON(w io.Writer, v interface{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// writeJSONFile replaces the file at path with v as JSON. It is written to a
+// temporary file next to it first and renamed into place, so readers never
+// see a partly written file.
+func writeJSONFile(path string, v interface{}) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
 	if err != nil {
-		return time.Time{}, err
+		return err
 	}
+	defer os.Remove(tmp.Name())
 
-	cmd := exec.Command(git, "-C", worktreePath, "log", "-1", "--format=%at")
-	output, err := cmd.Output()
+	if err := tmp.Chmod(0o644); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := writeJSON(tmp, v); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func getWorktreeInfo(ctx context.Context) ([]WorktreeInfo, error) {
+	infos, err := worktree.ListContext(ctx)
 	if err != nil {
-		return time.Time{}, err
+		return nil, err
+	}
+
+	worktrees := make([]WorktreeInfo, 0, len(infos))
+	seen := map[string]string{}
+	for _, info := range infos {
+		// A worktree registered through a symlink to another one is the same
+		// tree; listing it twice would have clean remove it twice
+		realPath := worktree.RealPath(info.Path)
+		if first, ok := seen[realPath]; ok {
+			fmt.Fprint(os.Stderr, plainText(fmt.Sprintf("⚠️  Ignoring worktree %s: it is the same directory as %s\n", info.Path, first)))
+			continue
+		}
+		seen[realPath] = info.Path
+
+		wt := WorktreeInfo{Info: info}
+		// Try to extract PR number from branch name, then from the path
+		wt.PRNumber = worktree.PRNumber(wt.Branch)
+		if wt.PRNumber != 0 {
+			logf(levelDebug, "%s: PR #%d from branch name %s", filepath.Base(wt.Path), wt.PRNumber, wt.Branch)
+		} else if wt.PRNumber = worktree.PRNumber(filepath.Base(wt.Path)); wt.PRNumber != 0 {
+			logf(levelDebug, "%s: PR #%d from directory name", filepath.Base(wt.Path), wt.PRNumber)
+		}
+		if !wt.Inaccessible && !wt.LastCommit.IsZero() {
+			wt.DaysSinceCommit = int(time.Since(wt.LastCommit).Hours() / 24)
+		}
+		worktrees = append(worktrees, wt)
 	}
 
-	timestamp := strings.TrimSpace(string(output))
-	if timestamp == "" {
-		return time.Time{}, fmt.Errorf("no commits found")
+	return worktrees, nil
+}
+
+// checkRemovable returns an error describing why the worktree at path should
+// not be removed, or nil if it is safe to remove. The check is skipped when
+// force is set and for a worktree whose directory was deleted.
+func checkRemovable(ctx context.Context, path string, force bool) error {
+	// A worktree whose directory is already gone has nothing left to lose;
+	// removing it only prunes its metadata
+	if force || worktree.IsMissing(path) {
+		return nil
+	}
+	if partlyRemoved(path) {
+		return fmt.Errorf("worktree was partly removed and has no .git file left (use --force to finish removing it)")
 	}
 
-	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	dirty, err := hasUncommittedChanges(ctx, path)
 	if err != nil {
-		return time.Time{}, err
+		return fmt.Errorf("could not check for uncommitted changes: %w", err)
+	}
+	if dirty {
+		return fmt.Errorf("worktree has uncommitted changes (use --force to remove anyway)")
+	}
+	return nil
+}
+
+// checkPushed returns an error when the branch of the worktree at path has
+// commits its upstream does not have, e.g. follow-ups made after the PR was
+// merged. Branches without an upstream pass. The check is skipped when force
+// is set and for a worktree whose directory was deleted.
+func checkPushed(ctx context.Context, path string, force bool) error {
+	if force || worktree.IsMissing(path) {
+		return nil
 	}
 
-	return time.Unix(unix, 0), nil
+	unpushed, err := countUnpushedCommits(ctx, path)
+	if err != nil {
+		return fmt.Errorf("could not check for unpushed commits: %w", err)
+	}
+	if unpushed > 0 {
+		return fmt.Errorf("worktree has %d unpushed commit(s) (use --force to remove anyway)", unpushed)
+	}
+	return nil
 }
 
-func getPRStatus(repo interface{ Owner() string; Name() string }, prNumber int) (string, error) {
-	client, err := gh.RESTClient(nil)
+// countUnpushedCommits returns how many commits HEAD is ahead of its
+// upstream, or 0 when the branch has no upstream.
+func countUnpushedCommits(ctx context.Context, worktreePath string) (int, error) {
+	if _, err := gitOutput(ctx, "-C", worktreePath, "rev-parse", "--abbrev-ref", "@{u}"); err != nil {
+		return 0, nil
+	}
+
+	output, err := gitOutput(ctx, "-C", worktreePath, "log", "@{u}..HEAD", "--oneline")
 	if err != nil {
-		return "", err
+		return 0, err
 	}
+	if output == "" {
+		return 0, nil
+	}
+	return len(strings.Split(output, "\n")), nil
+}
 
-	var pr struct {
-		State  string
-		Merged bool `json:"merged"`
+// dirSize returns the total size of the regular files under path. Symlinks
+// are not followed so linked content is not counted twice.
+func dirSize(path string) (int64, error) {
+	var size int64
+	err := filepath.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		size += info.Size()
+		return nil
+	})
+	return size, err
+}
+
+// formatBytes renders n using binary units, e.g. "3.2 GiB".
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
 	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
 
-	err = client.Get(fmt.Sprintf("repos/%s/%s/pulls/%d", repo.Owner(), repo.Name(), prNumber), &pr)
+func hasUncommittedChanges(ctx context.Context, worktreePath string) (bool, error) {
+	cmd, err := gitCommand(ctx, "-C", worktreePath, "status", "--porcelain")
 	if err != nil {
-		return "", err
+		return false, err
 	}
 
-	if pr.Merged {
-		return "merged", nil
+	output, err := cmd.Output()
+	if err != nil {
+		return false, err
 	}
-	return pr.State, nil // "open" or "closed"
+
+	return strings.TrimSpace(string(output)) != "", nil
+}
+
+// removeWorktreeArgs returns the git arguments used to remove the worktree
+// at path. Without force, git refuses to remove a worktree with modified or
+// untracked files.
+func removeWorktreeArgs(path string, force bool) []string {
+	args := []string{"worktree", "remove", path}
+	if force {
+		args = append(args, "--force")
+	}
+	return args
 }
 
-func removeWorktree(path string) error {
-	git, err := safeexec.LookPath("git")
+// removeWorktree removes the worktree at path. When git refuses, its own
+// message is returned so the reason is clear.
+//
+// Removal is idempotent so an interrupted clean can simply be run again: a
+// path git no longer knows as a worktree was already removed, e.g. by an
+// overlapping run, and counts as success. A worktree whose directory was
+// deleted only has its administrative files left, which git drops without
+// complaint. With force, what is left of a worktree whose earlier removal
+// failed halfway is deleted before git drops its administrative files.
+func removeWorktree(ctx context.Context, path string, force bool) error {
+	if force && partlyRemoved(path) {
+		if err := os.RemoveAll(path); err != nil {
+			return fmt.Errorf("could not delete the rest of the partly removed worktree: %w", err)
+		}
+	}
+
+	cmd, err := gitCommand(ctx, removeWorktreeArgs(path, force)...)
+	if err != nil {
+		return err
+	}
+	output, err := cmd.CombinedOutput()
 	if err != nil {
+		msg := strings.TrimSpace(string(output))
+		if strings.Contains(msg, "is not a working tree") {
+			logf(levelInfo, "%s: already removed", filepath.Base(path))
+			return nil
+		}
+		if msg != "" {
+			return errors.New(msg)
+		}
 		return err
 	}
+	return nil
+}
 
-	cmd := exec.Command(git, "worktree", "remove", path, "--force")
-	return cmd.Run()
-}
\ No newline at end of file
+// partlyRemoved reports whether the worktree directory at path still exists
+// but lost its .git file, as happens when a removal fails halfway.
+func partlyRemoved(path string) bool {
+	if _, err := os.Stat(path); err != nil {
+		return false
+	}
+	_, err := os.Lstat(filepath.Join(path, ".git"))
+	return errors.Is(err, os.ErrNotExist)
+}
@@ -0,0 +1,209 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/eikster-dk/gh-worktree/internal/worktree"
+)
+
+// setStdin makes the prompts read input, as if typed in a terminal, for the
+// rest of the test.
+func setStdin(t *testing.T, input string) {
+	t.Helper()
+	saved, savedIsTerminal := stdin, stdinIsTerminal
+	stdin = bufio.NewReader(strings.NewReader(input))
+	stdinIsTerminal = func() bool { return true }
+	t.Cleanup(func() { stdin, stdinIsTerminal = saved, savedIsTerminal })
+}
+
+func TestConfirmReadsOneAnswerPerPrompt(t *testing.T) {
+	setStdin(t, "y\nno\nYES\n")
+
+	want := []bool{true, false, true, false}
+	for i, w := range want {
+		if got := confirm(io.Discard, "Proceed?"); got != w {
+			t.Errorf("answer %d: confirm() = %v, want %v", i+1, got, w)
+		}
+	}
+}
+
+func TestChooseBranchAfterConfirm(t *testing.T) {
+	setStdin(t, "y\n2\n")
+
+	if !confirm(io.Discard, "Proceed?") {
+		t.Fatal("confirm() = false, want true")
+	}
+	branch, err := chooseBranch(io.Discard, []string{"a", "b"})
+	if err != nil {
+		t.Fatalf("chooseBranch() error = %v", err)
+	}
+	if branch != "b" {
+		t.Errorf("chooseBranch() = %q, want %q", branch, "b")
+	}
+}
+
+func TestChooseBranchWithoutTerminal(t *testing.T) {
+	setStdin(t, "1\n")
+	stdinIsTerminal = func() bool { return false }
+
+	if branch, err := chooseBranch(io.Discard, []string{"a", "b"}); err == nil {
+		t.Errorf("chooseBranch() = %q, want an error when stdin is not a terminal", branch)
+	}
+}
+
+func TestPromptForWorktreesAfterConfirm(t *testing.T) {
+	worktrees := []WorktreeInfo{{PRNumber: 1}, {PRNumber: 2}, {PRNumber: 3}}
+	tests := []struct {
+		name   string
+		answer string
+		want   []int
+	}{
+		{"numbers", "1 3", []int{1, 3}},
+		{"all", "all", []int{1, 2, 3}},
+		{"out of range and garbage", "0 4 x 2", []int{2}},
+		{"empty", "", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setStdin(t, "y\n"+tt.answer+"\n")
+
+			if !confirm(io.Discard, "Proceed?") {
+				t.Fatal("confirm() = false, want true")
+			}
+			var got []int
+			for _, wt := range promptForWorktrees(worktrees) {
+				got = append(got, wt.PRNumber)
+			}
+			if !equalInts(got, tt.want) {
+				t.Errorf("promptForWorktrees() chose %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func equalInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestRemoveAfterPartialClean(t *testing.T) {
+	dir := newTestRepo(t)
+	base, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	deleted := filepath.Join(base, "deleted")
+	partly := filepath.Join(base, "partly")
+	intact := filepath.Join(base, "intact")
+	for _, path := range []string{deleted, partly, intact} {
+		runGit(t, dir, "worktree", "add", "-q", "-b", filepath.Base(path), path)
+	}
+
+	// An earlier run was interrupted: one directory was deleted outright,
+	// another lost its .git file before git dropped its administrative files
+	if err := os.RemoveAll(deleted); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(partly, ".git")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := checkRemovable(context.Background(), deleted, false); err != nil {
+		t.Errorf("checkRemovable(context.Background(), deleted) error = %v, want nil", err)
+	}
+	if err := checkPushed(context.Background(), deleted, false); err != nil {
+		t.Errorf("checkPushed(context.Background(), deleted) error = %v, want nil", err)
+	}
+	if err := checkRemovable(context.Background(), partly, false); err == nil {
+		t.Error("checkRemovable(context.Background(), partly) error = nil, want an error without --force")
+	}
+	if err := checkRemovable(context.Background(), intact, false); err != nil {
+		t.Errorf("checkRemovable(context.Background(), intact) error = %v, want nil", err)
+	}
+
+	if err := removeWorktree(context.Background(), deleted, false); err != nil {
+		t.Errorf("removeWorktree(context.Background(), deleted) error = %v", err)
+	}
+	if err := removeWorktree(context.Background(), partly, true); err != nil {
+		t.Errorf("removeWorktree(context.Background(), partly, force) error = %v", err)
+	}
+	// Running again finds both already removed
+	for _, path := range []string{deleted, partly} {
+		if err := removeWorktree(context.Background(), path, true); err != nil {
+			t.Errorf("removeWorktree(context.Background(), %s) again error = %v", filepath.Base(path), err)
+		}
+	}
+
+	worktrees, err := worktree.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var paths []string
+	for _, wt := range worktrees {
+		paths = append(paths, wt.Path)
+	}
+	if len(paths) != 2 || paths[0] != dir || paths[1] != intact {
+		t.Errorf("worktrees after removal = %v, want [%s %s]", paths, dir, intact)
+	}
+	if _, err := os.Stat(partly); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("partly removed directory still exists: %v", err)
+	}
+}
+
+func TestWithinClosedGrace(t *testing.T) {
+	grace := 14 * 24 * time.Hour
+	tests := []struct {
+		name       string
+		lastCommit time.Time
+		want       bool
+	}{
+		{"recent activity", time.Now().Add(-24 * time.Hour), true},
+		{"inactive", time.Now().Add(-30 * 24 * time.Hour), false},
+		{"unknown activity", time.Time{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wt := WorktreeInfo{Info: worktree.Info{LastCommit: tt.lastCommit}, PRStatus: "closed"}
+			if got := withinClosedGrace(wt, grace); got != tt.want {
+				t.Errorf("withinClosedGrace() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLastActivity(t *testing.T) {
+	dir := newTestRepo(t)
+	unborn := filepath.Join(filepath.Dir(dir), filepath.Base(dir)+"-unborn")
+	runGit(t, dir, "worktree", "add", "-q", "--detach", unborn)
+	runGit(t, unborn, "checkout", "-q", "--orphan", "unborn")
+
+	worktrees, err := getWorktreeInfo(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{dir: "0 days ago", unborn: "unknown"}
+	for _, wt := range worktrees {
+		if got := wt.lastActivity(); got != want[wt.Path] {
+			t.Errorf("%s: lastActivity() = %q, want %q", filepath.Base(wt.Path), got, want[wt.Path])
+		}
+		if wt.LastCommit.IsZero() && wt.DaysSinceCommit != 0 {
+			t.Errorf("%s: DaysSinceCommit = %d for an unknown last commit, want 0", filepath.Base(wt.Path), wt.DaysSinceCommit)
+		}
+	}
+}
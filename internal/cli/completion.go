@@ -0,0 +1,67 @@
+package cli
+
+import (
+	"strings"
+
+	"github.com/eikster-dk/gh-worktree/internal/worktree"
+	"github.com/spf13/cobra"
+)
+
+// completeWorktreeBranches completes the first argument with the branches
+// that currently have a worktree.
+func completeWorktreeBranches(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	branches, err := worktree.CheckedOutBranches(cmd.Context())
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	return filterPrefix(branches, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeWorktreeBranchList completes every argument with the branches that
+// currently have a worktree and are not already given.
+func completeWorktreeBranchList(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	branches, err := worktree.CheckedOutBranches(cmd.Context())
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	given := map[string]bool{}
+	for _, arg := range args {
+		given[arg] = true
+	}
+	var remaining []string
+	for _, branch := range branches {
+		if !given[branch] {
+			remaining = append(remaining, branch)
+		}
+	}
+	return filterPrefix(remaining, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeAllBranches completes the first argument with local and remote
+// branch names.
+func completeAllBranches(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	branches, err := worktree.AllBranches(cmd.Context())
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	return filterPrefix(branches, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+func filterPrefix(values []string, prefix string) []string {
+	var filtered []string
+	for _, v := range values {
+		if strings.HasPrefix(v, prefix) {
+			filtered = append(filtered, v)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,49 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/eikster-dk/gh-worktree/internal/config"
+	"github.com/eikster-dk/gh-worktree/internal/worktree"
+)
+
+var (
+	configMu      sync.Mutex
+	loadedConfigs = map[string]config.Config{}
+)
+
+// loadConfig loads the config file of the repository in worktree.Dir once
+// per run and directory. Problems reading it are reported as a warning and
+// result in an empty config.
+func loadConfig(ctx context.Context) config.Config {
+	configMu.Lock()
+	defer configMu.Unlock()
+	if cfg, ok := loadedConfigs[worktree.Dir]; ok {
+		return cfg
+	}
+
+	var cfg config.Config
+	if root, err := worktree.RepoRoot(ctx); err == nil {
+		if cfg, err = config.Load(root); err != nil {
+			fmt.Fprint(os.Stderr, plainText(fmt.Sprintf("⚠️  Ignoring config file: %v\n", err)))
+		}
+	}
+	loadedConfigs[worktree.Dir] = cfg
+	return cfg
+}
+
+// usePRNumberPatterns makes PR numbers be read with the --pr-pattern
+// patterns, then those from the config file of the repository in
+// worktree.Dir, and then the built-in ones. It replaces the patterns of the
+// repository used before, so call it again whenever worktree.Dir changes.
+func usePRNumberPatterns(ctx context.Context) error {
+	cfg := loadConfig(ctx)
+	if err := worktree.SetPRNumberPatterns(cfg.PRPatterns); err != nil {
+		return fmt.Errorf("%s: %w", config.FileName, err)
+	}
+	patterns := append(append([]string{}, prPatterns...), cfg.PRPatterns...)
+	return worktree.SetPRNumberPatterns(patterns)
+}
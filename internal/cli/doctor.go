@@ -0,0 +1,142 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/cli/go-gh/pkg/auth"
+	"github.com/cli/go-gh/pkg/repository"
+	"github.com/cli/safeexec"
+	"github.com/spf13/cobra"
+)
+
+func NewDoctor() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Check that git, the repository and GitHub access are set up correctly",
+		Long: `Check that git, the repository and GitHub access are set up correctly.
+
+Each check is printed with its result and, when it fails, a hint on how to fix
+it. doctor exits with 1 when git, the repository, gh authentication or the
+GitHub API is not usable.`,
+		Example: "gh worktree doctor",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			d := &doctor{out: newOutput(false)}
+			d.run(cmd.Context())
+			if d.failed > 0 {
+				// The checklist already says what is wrong
+				cmd.SilenceUsage = true
+				return ExitError{Code: 1}
+			}
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+// doctor prints the result of each check and counts the failed critical
+// checks.
+type doctor struct {
+	out    *output
+	failed int
+}
+
+func (d *doctor) pass(name string, format string, a ...interface{}) {
+	d.out.Essentialf("✅ %s: %s\n", name, fmt.Sprintf(format, a...))
+}
+
+// fail reports a failed critical check with a hint on how to fix it.
+func (d *doctor) fail(name string, err error, hint string) {
+	d.failed++
+	d.out.Essentialf("❌ %s: %v\n", name, err)
+	if hint != "" {
+		d.out.Essentialf("   %s\n", hint)
+	}
+}
+
+// warn reports a problem that does not keep gh worktree from working.
+func (d *doctor) warn(name string, format string, a ...interface{}) {
+	d.out.Essentialf("⚠️  %s: %s\n", name, fmt.Sprintf(format, a...))
+}
+
+func (d *doctor) run(ctx context.Context) {
+	git, err := safeexec.LookPath("git")
+	if err != nil {
+		d.fail("git", err, "Install git and make sure it is on your PATH")
+		return
+	}
+	if version, err := gitOutput(ctx, "--version"); err == nil {
+		d.pass("git", "%s (%s)", git, version)
+	} else {
+		d.fail("git", err, "Check that "+git+" is a working git executable")
+		return
+	}
+
+	if dir, err := gitOutput(ctx, "rev-parse", "--absolute-git-dir"); err == nil {
+		d.pass("repository", "%s", dir)
+	} else {
+		d.fail("repository", err, "Run gh worktree inside a git repository, or point -C at one")
+		return
+	}
+
+	// Without a GitHub repository the API checks still run against the
+	// default host, so authentication problems are reported either way.
+	host, _ := auth.DefaultHost()
+	if hostnameOverride != "" {
+		host = hostnameOverride
+	}
+	repo, err := currentRepository()
+	if err == nil {
+		host = repo.Host()
+		d.pass("GitHub repository", "%s/%s on %s", repo.Owner(), repo.Name(), host)
+	} else {
+		d.warn("GitHub repository", "%v; PR statuses can't be looked up (use --repo OWNER/REPO to choose one)", err)
+	}
+
+	if offline {
+		d.warn("authentication", "skipped because of --offline")
+		return
+	}
+
+	token, source := auth.TokenForHost(host)
+	if token == "" {
+		hint := fmt.Sprintf("Run `gh auth login --hostname %s`", host)
+		if host != "github.com" && !knownHost(host) {
+			hint += fmt.Sprintf("; gh knows no account on %s, so check --hostname and the remote URL for a GitHub Enterprise server", host)
+		}
+		d.fail("authentication", fmt.Errorf("not logged in to %s", host), hint)
+		return
+	}
+	d.pass("authentication", "logged in to %s (token from %s)", host, source)
+
+	if repo == nil {
+		repo, _ = repository.ParseWithHost("cli/cli", host)
+	}
+	client, err := restClient(repo)
+	if err != nil {
+		d.fail("GitHub API", err, "")
+		return
+	}
+	var user struct {
+		Login string `json:"login"`
+	}
+	explainf("+ GET user")
+	if err := client.DoWithContext(ctx, "GET", "user", nil, &user); err != nil {
+		d.fail("GitHub API", err, fmt.Sprintf("Check your network connection and that https://%s is reachable; run `gh auth status` to check the token", host))
+		return
+	}
+	d.pass("GitHub API", "reachable as %s", user.Login)
+}
+
+// knownHost reports whether gh has an account configured for host.
+func knownHost(host string) bool {
+	for _, h := range auth.KnownHosts() {
+		if strings.EqualFold(h, host) {
+			return true
+		}
+	}
+	return false
+}
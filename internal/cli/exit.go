@@ -0,0 +1,21 @@
+package cli
+
+import "fmt"
+
+// ExitError asks main to exit with Code without printing anything. Commands
+// return it when the exit code itself is the result, as clean --exit-code
+// does.
+type ExitError struct {
+	Code int
+}
+
+func (e ExitError) Error() string {
+	return fmt.Sprintf("exit status %d", e.Code)
+}
+
+// Exit codes of clean --exit-code. 1 is left for errors.
+const (
+	cleanExitNothing = 0
+	cleanExitRemoved = 2
+	cleanExitFailed  = 3
+)
@@ -0,0 +1,96 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+
+	"github.com/cli/safeexec"
+	"github.com/eikster-dk/gh-worktree/internal/worktree"
+)
+
+// explainOut receives every git command and GitHub API request made while
+// explain mode is on. It is nil otherwise.
+var explainOut io.Writer
+
+// setExplain turns explain mode on, tracing to w. Git commands run by the
+// worktree package are traced as well.
+func setExplain(w io.Writer) {
+	explainOut = w
+	worktree.Trace = func(args []string) {
+		explainf("+ %s", formatCommand(args))
+	}
+}
+
+// explainf writes a line to explainOut when explain mode is on.
+func explainf(format string, a ...interface{}) {
+	if explainOut != nil {
+		fmt.Fprintf(explainOut, format+"\n", a...)
+	}
+}
+
+// gitCmd is a git command that is killed when its context is done or it runs
+// longer than worktree.GitTimeout. Its Run, Output and CombinedOutput methods
+// report a timeout as such instead of as a killed process.
+type gitCmd struct {
+	*exec.Cmd
+	parent context.Context
+	ctx    context.Context
+	cancel context.CancelFunc
+	args   []string
+}
+
+// gitCommand returns a command running git with args that is killed when
+// ctx is done. All git invocations go through here so explain mode can print
+// them and the timeout applies.
+func gitCommand(ctx context.Context, args ...string) (*gitCmd, error) {
+	git, err := safeexec.LookPath("git")
+	if err != nil {
+		return nil, err
+	}
+	explainf("+ %s", formatCommand(args))
+	timeoutCtx, cancel := worktree.WithGitTimeout(ctx)
+	c := exec.CommandContext(timeoutCtx, git, args...)
+	c.Dir = worktree.Dir
+	return &gitCmd{Cmd: c, parent: ctx, ctx: timeoutCtx, cancel: cancel, args: args}, nil
+}
+
+func (c *gitCmd) Run() error {
+	defer c.cancel()
+	return c.checkTimeout(c.Cmd.Run())
+}
+
+func (c *gitCmd) Output() ([]byte, error) {
+	defer c.cancel()
+	output, err := c.Cmd.Output()
+	return output, c.checkTimeout(err)
+}
+
+func (c *gitCmd) CombinedOutput() ([]byte, error) {
+	defer c.cancel()
+	output, err := c.Cmd.CombinedOutput()
+	return output, c.checkTimeout(err)
+}
+
+func (c *gitCmd) checkTimeout(err error) error {
+	if err != nil && c.parent.Err() == nil && errors.Is(c.ctx.Err(), context.DeadlineExceeded) {
+		return worktree.TimeoutError(c.args)
+	}
+	return err
+}
+
+// formatCommand renders a git argv for display, quoting arguments that
+// would otherwise be split or expanded by a shell.
+func formatCommand(args []string) string {
+	parts := []string{"git"}
+	for _, arg := range args {
+		if arg == "" || strings.ContainsAny(arg, " \t\n'\"$`\\*?[]{}()<>|&;~#") {
+			arg = shellQuote(arg)
+		}
+		parts = append(parts, arg)
+	}
+	return strings.Join(parts, " ")
+}
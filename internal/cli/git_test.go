@@ -0,0 +1,66 @@
+package cli
+
+import (
+	"context"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/eikster-dk/gh-worktree/internal/worktree"
+)
+
+// newTestRepo creates a repository with one commit on main and makes it the
+// directory git commands run in for the rest of the test.
+func newTestRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	for _, name := range []string{"GIT_AUTHOR_NAME", "GIT_COMMITTER_NAME"} {
+		t.Setenv(name, "test")
+	}
+	for _, name := range []string{"GIT_AUTHOR_EMAIL", "GIT_COMMITTER_EMAIL"} {
+		t.Setenv(name, "test@example.com")
+	}
+	t.Setenv("GIT_CONFIG_GLOBAL", "/dev/null")
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, dir, "init", "-q", "-b", "main")
+	runGit(t, dir, "commit", "-q", "--allow-empty", "-m", "initial")
+
+	saved := worktree.Dir
+	worktree.Dir = dir
+	t.Cleanup(func() { worktree.Dir = saved })
+	return dir
+}
+
+// runGit runs git with args in dir and returns its trimmed output.
+func runGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	c := exec.Command("git", args...)
+	c.Dir = dir
+	output, err := c.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, output)
+	}
+	return strings.TrimSpace(string(output))
+}
+
+func TestGitOutputCanceled(t *testing.T) {
+	newTestRepo(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := gitOutput(ctx, "rev-parse", "HEAD")
+	if err == nil {
+		t.Fatal("gitOutput() error = nil, want an error for a canceled context")
+	}
+	if strings.Contains(err.Error(), "timed out") {
+		t.Errorf("gitOutput() error = %v, want the cancellation rather than a timeout", err)
+	}
+}
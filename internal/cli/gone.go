@@ -0,0 +1,67 @@
+package cli
+
+import (
+	"context"
+	"strings"
+)
+
+// markGoneUpstreams sets UpstreamGone on the worktrees whose branch tracks a
+// branch that no longer exists on its remote, typically because it was
+// deleted after its PR was merged. Each remote is asked once with git
+// ls-remote, so the result does not depend on a prior fetch --prune. Remotes
+// that can't be reached are reported with logf and leave their worktrees
+// unmarked.
+func markGoneUpstreams(ctx context.Context, worktrees []WorktreeInfo) {
+	output, err := gitOutput(ctx, "for-each-ref", "--format=%(refname)%00%(upstream:remotename)%00%(upstream:remoteref)", "refs/heads")
+	if err != nil {
+		logf(levelInfo, "could not read branch upstreams: %v", err)
+		return
+	}
+
+	type upstream struct{ remote, ref string }
+	upstreams := map[string]upstream{}
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Split(line, "\x00")
+		// Branches tracking another local branch have "." as their remote
+		if len(fields) != 3 || fields[1] == "" || fields[1] == "." || fields[2] == "" {
+			continue
+		}
+		upstreams[strings.TrimPrefix(fields[0], "refs/heads/")] = upstream{remote: fields[1], ref: fields[2]}
+	}
+
+	heads := map[string]map[string]bool{}
+	for i := range worktrees {
+		wt := &worktrees[i]
+		u, ok := upstreams[wt.Branch]
+		if !ok || wt.Detached {
+			continue
+		}
+
+		remoteHeads, listed := heads[u.remote]
+		if !listed {
+			remoteHeads = listRemoteHeads(ctx, u.remote)
+			heads[u.remote] = remoteHeads
+		}
+		if remoteHeads != nil && !remoteHeads[u.ref] {
+			wt.UpstreamGone = true
+		}
+	}
+}
+
+// listRemoteHeads returns the branch refs on remote, e.g. refs/heads/main, or
+// nil if the remote can't be listed.
+func listRemoteHeads(ctx context.Context, remote string) map[string]bool {
+	output, err := gitOutput(ctx, "ls-remote", "--heads", remote)
+	if err != nil {
+		logf(levelInfo, "could not list the branches of remote %s: %v", remote, err)
+		return nil
+	}
+
+	refs := map[string]bool{}
+	for _, line := range strings.Split(output, "\n") {
+		if _, ref, ok := strings.Cut(line, "\t"); ok {
+			refs[ref] = true
+		}
+	}
+	return refs
+}
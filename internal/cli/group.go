@@ -0,0 +1,104 @@
+package cli
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// worktreeGroup is a set of worktrees sharing the key clean --group-by
+// derived from them.
+type worktreeGroup struct {
+	Key       string
+	Worktrees []WorktreeInfo
+}
+
+// groupKey returns the key of wt when grouping by author, status or prefix.
+func groupKey(wt WorktreeInfo, by string) string {
+	switch by {
+	case "author":
+		if wt.PRNumber == 0 || wt.PRStatus == "" {
+			return "(no PR)"
+		}
+		if wt.PRAuthor == "" {
+			return "(unknown author)"
+		}
+		return wt.PRAuthor
+	case "status":
+		switch {
+		case wt.PRNumber > 0 && wt.PRStatus != "":
+			return wt.displayPRStatus()
+		case wt.UpstreamGone:
+			return "upstream gone"
+		case wt.MergedIntoDefault:
+			return "merged into default branch"
+		}
+		return "(no PR)"
+	case "prefix":
+		if wt.Detached {
+			return "(detached)"
+		}
+		if prefix, _, ok := strings.Cut(wt.Branch, "/"); ok {
+			return prefix + "/"
+		}
+		return "(no prefix)"
+	}
+	return ""
+}
+
+// groupWorktrees groups worktrees by the key named by, largest groups first
+// and then by key. Worktrees keep their order within a group.
+func groupWorktrees(worktrees []WorktreeInfo, by string) ([]worktreeGroup, error) {
+	switch by {
+	case "author", "status", "prefix":
+	default:
+		return nil, fmt.Errorf("invalid group key %q: must be one of author, status, prefix", by)
+	}
+
+	var groups []worktreeGroup
+	index := map[string]int{}
+	for _, wt := range worktrees {
+		key := groupKey(wt, by)
+		i, ok := index[key]
+		if !ok {
+			i = len(groups)
+			index[key] = i
+			groups = append(groups, worktreeGroup{Key: key})
+		}
+		groups[i].Worktrees = append(groups[i].Worktrees, wt)
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		if len(groups[i].Worktrees) != len(groups[j].Worktrees) {
+			return len(groups[i].Worktrees) > len(groups[j].Worktrees)
+		}
+		return groups[i].Key < groups[j].Key
+	})
+	return groups, nil
+}
+
+// printGroups prints the removed and stale worktrees of result grouped by
+// the key named by, one line per group with its count and directory names.
+func printGroups(out *output, result cleanResult, by string) {
+	sections := []struct {
+		title     string
+		worktrees []WorktreeInfo
+	}{
+		{"🧹 Removed", result.Removed},
+		{"📅 Stale", result.Stale},
+	}
+	for _, section := range sections {
+		if len(section.worktrees) == 0 {
+			continue
+		}
+		groups, _ := groupWorktrees(section.worktrees, by)
+		out.Essentialf("\n%s by %s:\n\n", section.title, by)
+		for _, group := range groups {
+			names := make([]string, len(group.Worktrees))
+			for i, wt := range group.Worktrees {
+				names[i] = filepath.Base(wt.Path)
+			}
+			out.Essentialf("  %s (%d): %s\n", group.Key, len(group.Worktrees), strings.Join(names, ", "))
+		}
+	}
+}
@@ -0,0 +1,262 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"text/tabwriter"
+	"text/template"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// listResult is the document printed by list when --json is set.
+type listResult struct {
+	Worktrees []WorktreeInfo `json:"worktrees"`
+}
+
+func NewList() *cobra.Command {
+	var jsonOutput bool
+	var sortBy string
+	var noCache bool
+	var cacheTTL time.Duration
+	var format string
+	var detailed bool
+	var tsv bool
+	var all bool
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List worktrees with their associated PRs",
+		Long: `Lists all worktrees besides main with their branch, upstream, PR number, PR status, whether
+the branch is merged into the default branch, last commit age and lock state.
+--all also lists the main worktree (or the bare repository), marked as such
+in the BRANCH column and with isMain (or bare) set in --json.
+Worktrees whose directory can't be read, e.g. on an unmounted volume, show
+"inaccessible" instead of a commit age, or "missing" when the directory does
+not exist but its parent does. Worktrees whose last commit date
+can't be read show "unknown".
+
+--format prints each worktree with a Go template instead of the table. It can use
+the fields shown by --json by their Go names: .Path, .Branch, .Head, .Detached,
+.Upstream, .Locked, .LockReason, .LastCommit, .Inaccessible, .Missing,
+.PRNumber, .PRStatus, .Draft, .Mergeable, .ReviewDecision, .Checks,
+.MergedIntoDefault, .PRRepo and .DaysSinceCommit.
+
+--tsv prints one tab separated line per worktree with its path, branch, PR
+number and PR status, without a header, for fzf and awk. Empty fields stay
+empty and tabs or newlines inside fields are replaced by spaces.
+
+--detailed adds the review decision, mergeable state and combined status of the
+checks of each PR, turning the list into a small review dashboard.`,
+		Example: `gh worktree list --sort age
+gh worktree list --all
+gh worktree list --detailed
+gh worktree list --tsv | fzf | cut -f1
+gh worktree list --format '{{.Branch}} {{.PRNumber}} {{.PRStatus}}'`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if jsonOutput && format != "" {
+				return fmt.Errorf("--json and --format cannot be used together")
+			}
+			if tsv && (jsonOutput || format != "") {
+				return fmt.Errorf("--tsv cannot be used with --json or --format")
+			}
+			var tmpl *template.Template
+			if format != "" {
+				var err error
+				if tmpl, err = template.New("format").Parse(format); err != nil {
+					return fmt.Errorf("invalid --format template: %w", err)
+				}
+			}
+
+			worktrees, err := getWorktreeInfo(cmd.Context())
+			if err != nil {
+				return fmt.Errorf("failed to get worktree info: %w", err)
+			}
+
+			var listed []WorktreeInfo
+			for _, wt := range worktrees {
+				if (wt.IsMain || wt.Bare) && !all {
+					continue
+				}
+				listed = append(listed, wt)
+			}
+
+			if repos, err := resolveRepositories(cmd.Context()); err == nil {
+				if err := checkAPI(repos[0].Host()); errors.Is(err, errNotAuthenticated) {
+					fmt.Fprintf(os.Stderr, "Not logged in to %s, only cached PR statuses are shown. Run `gh auth login` to enable PR status checks\n", repos[0].Host())
+				}
+				var cache *prStatusCache
+				if !noCache {
+					cache = loadPRStatusCache(cacheTTL)
+				}
+				fetchPRStatuses(cmd.Context(), repos, listed, cache, newProgress("Checking PR status", !jsonOutput && !tsv && tmpl == nil))
+			}
+			if err := cmd.Context().Err(); err != nil {
+				return err
+			}
+
+			markMergedIntoDefault(cmd.Context(), listed)
+
+			if err := sortWorktrees(listed, sortBy); err != nil {
+				return err
+			}
+
+			if jsonOutput {
+				if listed == nil {
+					listed = []WorktreeInfo{}
+				}
+				return printJSON(listResult{Worktrees: listed})
+			}
+
+			if tmpl != nil {
+				for _, wt := range listed {
+					if err := tmpl.Execute(os.Stdout, wt); err != nil {
+						return fmt.Errorf("could not execute --format template: %w", err)
+					}
+					fmt.Println()
+				}
+				return nil
+			}
+
+			if tsv {
+				for _, wt := range listed {
+					pr := ""
+					if wt.PRNumber > 0 {
+						pr = strconv.Itoa(wt.PRNumber)
+					}
+					fmt.Printf("%s\t%s\t%s\t%s\n", tsvField(wt.Path), tsvField(wt.displayBranch()), pr, tsvField(wt.displayPRStatus()))
+				}
+				return nil
+			}
+
+			if len(listed) == 0 {
+				fmt.Println("No worktrees found besides main.")
+				return nil
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			header := "PATH\tBRANCH\tUPSTREAM\tPR\tSTATUS\tMERGED\tLAST COMMIT\tLOCKED"
+			if detailed {
+				header += "\tREVIEW\tMERGEABLE\tCHECKS"
+			}
+			fmt.Fprintln(w, header)
+			for _, wt := range listed {
+				pr := "-"
+				if wt.PRNumber > 0 {
+					pr = "#" + strconv.Itoa(wt.PRNumber)
+				}
+				status := wt.displayPRStatus()
+				if status == "" {
+					status = "-"
+				}
+				locked := "-"
+				if wt.Locked {
+					locked = "yes"
+					if wt.LockReason != "" {
+						locked = "yes: " + wt.LockReason
+					}
+				}
+				lastCommit := wt.lastActivity()
+				if wt.Missing {
+					lastCommit = "missing"
+				} else if wt.Inaccessible {
+					lastCommit = "inaccessible"
+				}
+				upstream := wt.Upstream
+				if upstream == "" {
+					upstream = "-"
+				}
+				merged := "-"
+				if wt.MergedIntoDefault {
+					merged = "yes"
+				}
+				branch := wt.displayBranch()
+				if wt.Bare {
+					branch, lastCommit = "(bare)", "-"
+				} else if wt.IsMain {
+					branch += " (main worktree)"
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s", wt.Path, branch, upstream, pr, status, merged, lastCommit, locked)
+				if detailed {
+					fmt.Fprintf(w, "\t%s\t%s\t%s", orDash(wt.ReviewDecision), orDash(wt.Mergeable), orDash(wt.Checks))
+				}
+				fmt.Fprintln(w)
+			}
+			return w.Flush()
+		},
+	}
+
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Print the worktrees as JSON")
+	cmd.Flags().StringVar(&format, "format", "", "Print each worktree with a Go template, e.g. '{{.Branch}} {{.PRNumber}}'")
+	cmd.Flags().BoolVar(&tsv, "tsv", false, "Print path, branch, PR number and PR status separated by tabs, without a header")
+	cmd.Flags().BoolVar(&detailed, "detailed", false, "Also show the review decision, mergeable state and checks status of each PR")
+	cmd.Flags().BoolVar(&all, "all", false, "Also list the main worktree (or the bare repository)")
+	cmd.Flags().StringVar(&sortBy, "sort", "", "Sort worktrees by one of: age, branch, pr, status")
+	cmd.Flags().BoolVar(&noCache, "no-cache", false, "Always fetch PR statuses from GitHub instead of the local cache")
+	cmd.Flags().DurationVar(&cacheTTL, "cache-ttl", 5*time.Minute, "How long cached open PR statuses are valid (merged/closed are cached for 7 days)")
+
+	return cmd
+}
+
+// orDash returns s, or "-" if it is empty, for table cells.
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+var tsvUnsafeRe = regexp.MustCompile(`[\t\r\n]`)
+
+// tsvField replaces the tabs and line breaks in s with spaces so it stays a
+// single field of a single line.
+func tsvField(s string) string {
+	return tsvUnsafeRe.ReplaceAllString(s, " ")
+}
+
+// prStatusOrder ranks PR statuses for sorting by status, those most likely
+// done with first. Worktrees without a known PR status come last.
+var prStatusOrder = map[string]int{"merged": 0, "closed": 1, "open": 2}
+
+func prStatusRank(wt WorktreeInfo) int {
+	if rank, ok := prStatusOrder[wt.PRStatus]; ok {
+		return rank
+	}
+	return len(prStatusOrder)
+}
+
+// sortWorktrees sorts worktrees in place. Age sorts oldest first, and status
+// groups merged, closed, open and no PR in that order, oldest first within
+// each group. An empty key keeps the order reported by git.
+func sortWorktrees(worktrees []WorktreeInfo, by string) error {
+	switch by {
+	case "":
+	case "age":
+		sort.SliceStable(worktrees, func(i, j int) bool {
+			return worktrees[i].LastCommit.Before(worktrees[j].LastCommit)
+		})
+	case "branch":
+		sort.SliceStable(worktrees, func(i, j int) bool {
+			return worktrees[i].Branch < worktrees[j].Branch
+		})
+	case "pr":
+		sort.SliceStable(worktrees, func(i, j int) bool {
+			return worktrees[i].PRNumber < worktrees[j].PRNumber
+		})
+	case "status":
+		sort.SliceStable(worktrees, func(i, j int) bool {
+			if ri, rj := prStatusRank(worktrees[i]), prStatusRank(worktrees[j]); ri != rj {
+				return ri < rj
+			}
+			return worktrees[i].LastCommit.Before(worktrees[j].LastCommit)
+		})
+	default:
+		return fmt.Errorf("invalid sort key %q: must be one of age, branch, pr, status", by)
+	}
+	return nil
+}
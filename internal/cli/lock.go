@@ -0,0 +1,78 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+func NewLock() *cobra.Command {
+	var reason string
+
+	cmd := &cobra.Command{
+		Use:     "lock <branch | pr-number>",
+		Short:   "Lock a worktree so prune and clean leave it alone",
+		Example: `gh worktree lock feature-x --reason "on external drive"`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(args) < 1 {
+				return errors.New("a branch name or pr number is required")
+			}
+
+			return nil
+		},
+		ValidArgsFunction: completeWorktreeBranches,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path, err := resolveWorktreePath(cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+
+			gitArgs := []string{"worktree", "lock"}
+			if reason != "" {
+				gitArgs = append(gitArgs, "--reason", reason)
+			}
+			if _, err := gitOutput(cmd.Context(), append(gitArgs, path)...); err != nil {
+				return fmt.Errorf("failed to lock worktree: %w", err)
+			}
+
+			newOutput(false).Essentialf("🔒 Locked %s\n", path)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&reason, "reason", "", "Reason the worktree is locked")
+
+	return cmd
+}
+
+func NewUnlock() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "unlock <branch | pr-number>",
+		Short:   "Unlock a locked worktree",
+		Example: "gh worktree unlock feature-x",
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(args) < 1 {
+				return errors.New("a branch name or pr number is required")
+			}
+
+			return nil
+		},
+		ValidArgsFunction: completeWorktreeBranches,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path, err := resolveWorktreePath(cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+
+			if _, err := gitOutput(cmd.Context(), "worktree", "unlock", path); err != nil {
+				return fmt.Errorf("failed to unlock worktree: %w", err)
+			}
+
+			newOutput(false).Essentialf("🔓 Unlocked %s\n", path)
+			return nil
+		},
+	}
+
+	return cmd
+}
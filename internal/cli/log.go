@@ -0,0 +1,26 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+)
+
+// logLevel is the verbosity a log line needs to be shown.
+type logLevel int
+
+const (
+	// levelInfo lines explain decisions, shown with -v.
+	levelInfo logLevel = iota + 1
+	// levelDebug lines show the details behind them, shown with -vv.
+	levelDebug
+)
+
+// verbosity is the number of times -v was given.
+var verbosity int
+
+// logf writes a line to stderr when verbosity is at least level.
+func logf(level logLevel, format string, a ...interface{}) {
+	if verbosity >= int(level) {
+		fmt.Fprintf(os.Stderr, format+"\n", a...)
+	}
+}
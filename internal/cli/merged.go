@@ -0,0 +1,82 @@
+package cli
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+)
+
+// markMergedIntoDefault sets MergedIntoDefault on the worktrees whose branch
+// is fully merged into the default branch, i.e. all its commits are
+// reachable from it. Branches without commits of their own, such as one just
+// created for new work, are reachable too but are not marked, see
+// hasOwnCommits. The main worktree, which usually has the default branch
+// itself checked out, is never marked. Nothing is marked when the default
+// branch can't be found.
+func markMergedIntoDefault(ctx context.Context, worktrees []WorktreeInfo) {
+	base := defaultBranchRef(ctx)
+	if base == "" {
+		logf(levelInfo, "could not find the default branch to check for merged branches")
+		return
+	}
+
+	output, err := gitOutput(ctx, "branch", "--merged", base, "--format=%(refname:short)")
+	if err != nil {
+		logf(levelInfo, "could not list the branches merged into %s: %v", base, err)
+		return
+	}
+
+	merged := map[string]bool{}
+	for _, branch := range strings.Split(output, "\n") {
+		merged[branch] = true
+	}
+	for i := range worktrees {
+		wt := &worktrees[i]
+		if wt.Branch == "" || wt.Detached || wt.IsMain || !merged[wt.Branch] {
+			continue
+		}
+		if !hasOwnCommits(ctx, wt.Branch, base) {
+			logf(levelInfo, "%s: branch %s has no commits of its own, not treating it as merged", filepath.Base(wt.Path), wt.Branch)
+			continue
+		}
+		wt.MergedIntoDefault = true
+	}
+}
+
+// hasOwnCommits reports whether branch carries work of its own rather than
+// only commits that were already on the default branch base. That is the case
+// when its tip is not on the first-parent history of base, as for a branch
+// merged with a merge commit, or when its reflog shows it moved since it was
+// created, as for a branch that was fast-forwarded into base. Whether the
+// branch was pushed doesn't matter: pushing a branch adds no commits to it.
+// Without a creation entry in the reflog, e.g. in bare repositories, which
+// keep no reflogs by default, only the first-parent history counts.
+func hasOwnCommits(ctx context.Context, branch string, base string) bool {
+	tip, err := gitOutput(ctx, "rev-parse", "refs/heads/"+branch)
+	if err != nil {
+		return false
+	}
+
+	// If tip is on the first-parent history of base, it is exactly as many
+	// first parents back as there are such commits not reachable from tip
+	count, err := gitOutput(ctx, "rev-list", "--first-parent", "--count", tip+".."+base)
+	if err != nil {
+		return false
+	}
+	onMainline, err := gitOutput(ctx, "rev-parse", base+"~"+count)
+	if err == nil && onMainline != tip {
+		return true
+	}
+
+	// The reflog lists the newest entry first, so the last one is the creation
+	output, err := gitOutput(ctx, "reflog", "show", "--format=%H %gs", "refs/heads/"+branch, "--")
+	if err != nil || output == "" {
+		return false
+	}
+	entries := strings.Split(output, "\n")
+	created, subject, _ := strings.Cut(entries[len(entries)-1], " ")
+	if !strings.HasPrefix(subject, "branch: Created from") {
+		return false
+	}
+	return tip != created
+}
@@ -0,0 +1,85 @@
+package cli
+
+import (
+	"context"
+	"testing"
+)
+
+func TestMarkMergedIntoDefault(t *testing.T) {
+	dir := newTestRepo(t)
+
+	// fresh: just created for new work, no commits of its own
+	runGit(t, dir, "branch", "fresh")
+	// done: has a commit that was merged into main
+	runGit(t, dir, "checkout", "-q", "-b", "done")
+	runGit(t, dir, "commit", "-q", "--allow-empty", "-m", "done")
+	runGit(t, dir, "checkout", "-q", "main")
+	runGit(t, dir, "merge", "-q", "--no-ff", "-m", "merge done", "done")
+	// wip: has a commit that is not on main
+	runGit(t, dir, "checkout", "-q", "-b", "wip")
+	runGit(t, dir, "commit", "-q", "--allow-empty", "-m", "wip")
+	runGit(t, dir, "checkout", "-q", "main")
+	// later: created from a commit main already had, and main moved on
+	runGit(t, dir, "branch", "later", "main~1")
+	// pushed: no commits of its own, but pushed and tracking its upstream
+	remote := t.TempDir()
+	runGit(t, remote, "init", "-q", "--bare")
+	runGit(t, dir, "remote", "add", "origin", remote)
+	runGit(t, dir, "branch", "pushed")
+	runGit(t, dir, "push", "-q", "-u", "origin", "pushed")
+
+	worktrees := []WorktreeInfo{}
+	for _, branch := range []string{"main", "fresh", "done", "wip", "later", "pushed"} {
+		wt := WorktreeInfo{}
+		wt.Branch = branch
+		if branch == "pushed" {
+			wt.Upstream = "origin/pushed"
+		}
+		wt.Path = "/worktrees/" + branch
+		wt.IsMain = branch == "main"
+		worktrees = append(worktrees, wt)
+	}
+	markMergedIntoDefault(context.Background(), worktrees)
+
+	want := map[string]bool{"main": false, "fresh": false, "done": true, "wip": false, "later": false, "pushed": false}
+	for _, wt := range worktrees {
+		if wt.MergedIntoDefault != want[wt.Branch] {
+			t.Errorf("%s: MergedIntoDefault = %v, want %v", wt.Branch, wt.MergedIntoDefault, want[wt.Branch])
+		}
+	}
+}
+
+func TestHasOwnCommits(t *testing.T) {
+	dir := newTestRepo(t)
+
+	runGit(t, dir, "branch", "fresh")
+	runGit(t, dir, "checkout", "-q", "-b", "moved")
+	runGit(t, dir, "commit", "-q", "--allow-empty", "-m", "moved")
+	runGit(t, dir, "checkout", "-q", "main")
+	// Bare repositories keep no reflogs by default
+	runGit(t, dir, "-c", "core.logAllRefUpdates=false", "branch", "nolog", "moved")
+	runGit(t, dir, "-c", "core.logAllRefUpdates=false", "branch", "nolog-mainline", "main")
+	// ff: fast-forwarded into main, so its tip is on main's first-parent history
+	runGit(t, dir, "checkout", "-q", "-b", "ff")
+	runGit(t, dir, "commit", "-q", "--allow-empty", "-m", "ff")
+	runGit(t, dir, "checkout", "-q", "main")
+	runGit(t, dir, "merge", "-q", "--ff-only", "ff")
+
+	tests := []struct {
+		branch string
+		base   string
+		want   bool
+	}{
+		{"fresh", "main", false},
+		{"moved", "main", true},
+		{"nolog", "main", true},
+		{"nolog-mainline", "main", false},
+		{"ff", "main", true},
+		{"fresh", "origin/main", false},
+	}
+	for _, tt := range tests {
+		if got := hasOwnCommits(context.Background(), tt.branch, tt.base); got != tt.want {
+			t.Errorf("hasOwnCommits(context.Background(), %q, %q) = %v, want %v", tt.branch, tt.base, got, tt.want)
+		}
+	}
+}
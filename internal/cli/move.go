@@ -0,0 +1,79 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/eikster-dk/gh-worktree/internal/worktree"
+	"github.com/spf13/cobra"
+)
+
+func NewMove() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "move <branch | pr-number> <destination>",
+		Short:   "Move the worktree for a branch or PR number to a new directory",
+		Example: "gh worktree move feature-x ../worktrees/feature-x",
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(args) < 2 {
+				return errors.New("a branch name or pr number and a destination are required")
+			}
+
+			return nil
+		},
+		ValidArgsFunction: completeWorktreeBranches,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			source, err := resolveWorktreePath(cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+
+			dest, err := worktree.AbsPath(args[1])
+			if err != nil {
+				return err
+			}
+
+			if _, err := os.Stat(dest); err == nil {
+				return fmt.Errorf("directory already exists at: %s\nPlease remove it or choose a different path", dest)
+			}
+
+			locked, err := isWorktreeLocked(cmd.Context(), source)
+			if err != nil {
+				return err
+			}
+			if locked {
+				return fmt.Errorf("worktree at %s is locked\nUnlock it with 'git worktree unlock' before moving it", source)
+			}
+
+			if err := moveWorktree(cmd.Context(), source, dest); err != nil {
+				return err
+			}
+
+			fmt.Printf("Moved worktree\n  from: %s\n  to:   %s\n", source, dest)
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+func isWorktreeLocked(ctx context.Context, path string) (bool, error) {
+	worktrees, err := getWorktreeInfo(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to get worktree info: %w", err)
+	}
+	for _, wt := range worktrees {
+		if worktree.SamePath(wt.Path, path) {
+			return wt.Locked, nil
+		}
+	}
+	return false, nil
+}
+
+func moveWorktree(ctx context.Context, source string, dest string) error {
+	if _, err := gitOutput(ctx, "worktree", "move", source, dest); err != nil {
+		return fmt.Errorf("failed to move worktree: %w", err)
+	}
+	return nil
+}
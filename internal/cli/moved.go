@@ -0,0 +1,76 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/cli/go-gh/pkg/api"
+	"github.com/cli/go-gh/pkg/repository"
+)
+
+// movedRepository returns the repository repo was renamed or transferred to,
+// or nil when it has not moved. GitHub redirects the REST API paths of moved
+// repositories to their new location, which the HTTP client follows, so the
+// current name is the full_name of the repository it ends up at.
+func movedRepository(ctx context.Context, repo repository.Repository) (repository.Repository, error) {
+	client, err := restClient(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		FullName string `json:"full_name"`
+	}
+	path := fmt.Sprintf("repos/%s/%s", repo.Owner(), repo.Name())
+	err = withRetry(ctx, func() error {
+		explainf("+ GET %s", path)
+		return client.DoWithContext(ctx, "GET", path, nil, &resp)
+	})
+	if isNotFound(err) {
+		return nil, fmt.Errorf("repository %s/%s does not exist or is not visible with your token", repo.Owner(), repo.Name())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.FullName == "" || strings.EqualFold(resp.FullName, repo.Owner()+"/"+repo.Name()) {
+		return nil, nil
+	}
+	return repository.ParseWithHost(resp.FullName, repo.Host())
+}
+
+// isNotFound reports whether err is a 404 from the REST API or a GraphQL
+// error for a repository that could not be resolved.
+func isNotFound(err error) bool {
+	var httpErr api.HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode == http.StatusNotFound
+	}
+	var gqlErr api.GQLError
+	if errors.As(err, &gqlErr) {
+		return gqlErr.Match("NOT_FOUND", "repository")
+	}
+	return false
+}
+
+// followMove returns the repository repo moved to, or repo itself when it
+// has not moved or the lookup failed. A move is remembered in cache unless it
+// is nil.
+func followMove(ctx context.Context, repo repository.Repository, cache *prStatusCache) repository.Repository {
+	moved, err := movedRepository(ctx, repo)
+	if err != nil {
+		logf(levelInfo, "could not check whether %s/%s moved: %v", repo.Owner(), repo.Name(), err)
+		return repo
+	}
+	if moved == nil {
+		return repo
+	}
+	logf(levelInfo, "%s/%s was renamed or transferred to %s/%s", repo.Owner(), repo.Name(), moved.Owner(), moved.Name())
+	if cache != nil {
+		cache.setMoved(repo, moved)
+	}
+	return moved
+}
@@ -0,0 +1,168 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/cli/go-gh/pkg/browser"
+	"github.com/cli/go-gh/pkg/repository"
+	"github.com/eikster-dk/gh-worktree/internal/worktree"
+	"github.com/spf13/cobra"
+)
+
+func NewOpenPr() *cobra.Command {
+	var noBrowser bool
+
+	cmd := &cobra.Command{
+		Use:   "open-pr [<branch | pr-number>]",
+		Short: "Open the PR of a worktree in the browser",
+		Long: `Opens the pull request page of the worktree for a branch or PR number, or of the
+worktree in the current directory when no argument is given.
+
+The PR number is taken from the branch or directory name. When neither contains
+one, the open PR whose head is the branch is looked up on GitHub. The PR is
+looked up in the same repositories as for list, so in fork-based workflows the
+URL points at the upstream repository the PR was opened in.`,
+		Example: `gh worktree open-pr
+gh worktree open-pr feature-x --no-browser`,
+		ValidArgsFunction: completeWorktreeBranches,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var target string
+			if len(args) > 0 {
+				target = args[0]
+			}
+
+			wt, err := findWorktree(cmd.Context(), target)
+			if err != nil {
+				return err
+			}
+
+			repos, err := resolveRepositories(cmd.Context())
+			if err != nil {
+				return fmt.Errorf("could not get current repository: %w", err)
+			}
+
+			repo, number, err := findWorktreePR(cmd.Context(), repos, wt)
+			if err != nil {
+				return err
+			}
+
+			prURL := fmt.Sprintf("https://%s/%s/%s/pull/%d", repo.Host(), repo.Owner(), repo.Name(), number)
+			if noBrowser {
+				fmt.Println(prURL)
+				return nil
+			}
+
+			fmt.Printf("Opening %s in your browser.\n", prURL)
+			b := browser.New("", os.Stdout, os.Stderr)
+			if err := b.Browse(prURL); err != nil {
+				return fmt.Errorf("could not open browser: %w", err)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&noBrowser, "no-browser", false, "Print the PR URL instead of opening it")
+
+	return cmd
+}
+
+// findWorktreePR returns the PR of wt and the repository it was found in,
+// trying repos in turn and following moved repositories. The open PR whose
+// head is the branch is looked up when wt has no PR number. A PR number that
+// can't be looked up, e.g. with --offline, is assumed to be in the first of
+// repos.
+func findWorktreePR(ctx context.Context, repos []repository.Repository, wt WorktreeInfo) (repository.Repository, int, error) {
+	if wt.PRNumber == 0 {
+		if wt.Branch == "" {
+			return nil, 0, fmt.Errorf("no PR number found for %s and it has no branch to look one up", wt.Path)
+		}
+		for _, repo := range repos {
+			number, _, err := findPRForBranch(ctx, repo, wt.Branch, "open")
+			if err != nil {
+				return nil, 0, err
+			}
+			if number != 0 {
+				return repo, number, nil
+			}
+		}
+		return nil, 0, fmt.Errorf("no open PR found for branch '%s'", wt.Branch)
+	}
+
+	worktrees := []WorktreeInfo{wt}
+	fetchPRStatuses(ctx, repos, worktrees, nil, nil)
+	if worktrees[0].PRRepo == "" {
+		return repos[0], wt.PRNumber, nil
+	}
+	repo, err := repository.ParseWithHost(worktrees[0].PRRepo, repos[0].Host())
+	if err != nil {
+		return nil, 0, err
+	}
+	return repo, wt.PRNumber, nil
+}
+
+// findWorktree returns the worktree for target, a branch name or PR number,
+// or the worktree containing the current directory when target is empty.
+func findWorktree(ctx context.Context, target string) (WorktreeInfo, error) {
+	if target == "" {
+		return currentWorktree(ctx)
+	}
+	path, err := resolveWorktreePath(ctx, target)
+	if err != nil {
+		return WorktreeInfo{}, err
+	}
+
+	worktrees, err := getWorktreeInfo(ctx)
+	if err != nil {
+		return WorktreeInfo{}, fmt.Errorf("failed to get worktree info: %w", err)
+	}
+	for _, wt := range worktrees {
+		if worktree.SamePath(wt.Path, path) {
+			return wt, nil
+		}
+	}
+	return WorktreeInfo{}, fmt.Errorf("no worktree found at %s", path)
+}
+
+// currentWorktree returns the worktree containing the current directory, or
+// the -C directory when set. Nested worktrees resolve to the innermost one.
+func currentWorktree(ctx context.Context) (WorktreeInfo, error) {
+	dir := worktree.Dir
+	if dir == "" {
+		var err error
+		if dir, err = os.Getwd(); err != nil {
+			return WorktreeInfo{}, err
+		}
+	}
+
+	worktrees, err := getWorktreeInfo(ctx)
+	if err != nil {
+		return WorktreeInfo{}, fmt.Errorf("failed to get worktree info: %w", err)
+	}
+	current, ok := worktreeContaining(worktrees, dir)
+	if !ok {
+		return WorktreeInfo{}, fmt.Errorf("%s is not inside a worktree; pass a branch name or PR number", dir)
+	}
+	return current, nil
+}
+
+// worktreeContaining returns the innermost of worktrees whose directory is
+// dir or one of its parents.
+func worktreeContaining(worktrees []WorktreeInfo, dir string) (WorktreeInfo, bool) {
+	dir = worktree.RealPath(dir)
+	var current WorktreeInfo
+	longest := -1
+	for _, wt := range worktrees {
+		if wt.Bare {
+			continue
+		}
+		root := worktree.RealPath(wt.Path)
+		if (dir == root || strings.HasPrefix(dir, root+string(filepath.Separator))) && len(root) > longest {
+			current, longest = wt, len(root)
+		}
+	}
+	return current, longest >= 0
+}
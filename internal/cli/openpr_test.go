@@ -0,0 +1,59 @@
+package cli
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/cli/go-gh/pkg/repository"
+)
+
+func TestFindWorktreePRUsesRepositoryItWasFoundIn(t *testing.T) {
+	fakeAPI(t, func(req *http.Request) (*http.Response, error) {
+		switch req.URL.Path {
+		case "/graphql":
+			body, _ := io.ReadAll(req.Body)
+			if strings.Contains(string(body), `"owner":"upstream"`) {
+				return jsonResponse(req, 200, `{"data": {"repository": {"pr7": {"state": "OPEN"}}}}`, nil), nil
+			}
+			return jsonResponse(req, 200, `{
+				"data": {"repository": {"pr7": null}},
+				"errors": [{"type": "NOT_FOUND", "path": ["repository", "pr7"], "message": "Could not resolve to a PullRequest with the number of 7."}]
+			}`, nil), nil
+		case "/repos/upstream/app/pulls":
+			return jsonResponse(req, 200, `[{"number": 8, "state": "open"}]`, nil), nil
+		case "/repos/acme/app/pulls":
+			return jsonResponse(req, 200, `[]`, nil), nil
+		}
+		return jsonResponse(req, 404, `{"message":"Not Found"}`, nil), nil
+	})
+
+	onBranch := WorktreeInfo{}
+	onBranch.Branch = "feature"
+
+	origin, _ := repository.Parse("acme/app")
+	upstream, _ := repository.Parse("upstream/app")
+	repos := []repository.Repository{origin, upstream}
+
+	tests := []struct {
+		name       string
+		wt         WorktreeInfo
+		wantRepo   string
+		wantNumber int
+	}{
+		{"PR number", WorktreeInfo{PRNumber: 7}, "upstream/app", 7},
+		{"branch", onBranch, "upstream/app", 8},
+	}
+	for _, tt := range tests {
+		repo, number, err := findWorktreePR(context.Background(), repos, tt.wt)
+		if err != nil {
+			t.Errorf("%s: findWorktreePR() error = %v", tt.name, err)
+			continue
+		}
+		if got := repo.Owner() + "/" + repo.Name(); got != tt.wantRepo || number != tt.wantNumber {
+			t.Errorf("%s: findWorktreePR() = %s#%d, want %s#%d", tt.name, got, number, tt.wantRepo, tt.wantNumber)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+
+	"golang.org/x/term"
+)
+
+// quiet is set by the global --quiet flag.
+var quiet bool
+
+// plain is set by the global --plain flag, and when NO_COLOR is set or
+// stdout is not a terminal.
+var plain bool
+
+var emojiRe = regexp.MustCompile(`[\p{So}\x{FE0F}\x{200D}]+ *`)
+
+// plainTags are the ASCII tags emoji are replaced with in plain mode. Emoji
+// missing here are dropped.
+var plainTags = map[string]string{
+	"✅":  "[ok]",
+	"✨":  "[ok]",
+	"❌":  "[error]",
+	"⚠️": "[warn]",
+	"🧹":  "[remove]",
+	"📅":  "[stale]",
+	"🔒":  "[locked]",
+	"🔓":  "[unlocked]",
+	"🏁":  "[done]",
+	"🔍":  "[info]",
+	"📋":  "[plan]",
+	"📴":  "[offline]",
+	"💾":  "[size]",
+	"🔧":  "[repaired]",
+	"👉":  "[current]",
+	"📂":  "[repo]",
+	"⏭️": "[skip]",
+}
+
+// plainText replaces the emoji in s with ASCII tags in plain mode, and
+// returns s unchanged otherwise.
+func plainText(s string) string {
+	if !plain {
+		return s
+	}
+	return emojiRe.ReplaceAllStringFunc(s, func(emoji string) string {
+		if tag, ok := plainTags[strings.TrimRight(emoji, " ")]; ok {
+			return tag + " "
+		}
+		return ""
+	})
+}
+
+// output writes human-readable command output. Decorative lines are dropped
+// in quiet mode, while essential lines (errors and summaries) are always
+// written with their emoji stripped.
+type output struct {
+	w     io.Writer
+	quiet bool
+}
+
+// newOutput returns an output writing to stdout, or discarding everything
+// when discard is set (e.g. because JSON is printed instead).
+func newOutput(discard bool) *output {
+	if discard {
+		return &output{w: io.Discard, quiet: quiet}
+	}
+	return &output{w: os.Stdout, quiet: quiet}
+}
+
+// Printf writes a decorative line that is suppressed in quiet mode.
+func (o *output) Printf(format string, a ...interface{}) {
+	if o.quiet {
+		return
+	}
+	fmt.Fprint(o.w, plainText(fmt.Sprintf(format, a...)))
+}
+
+// Println writes a decorative line that is suppressed in quiet mode.
+func (o *output) Println(a ...interface{}) {
+	if o.quiet {
+		return
+	}
+	fmt.Fprint(o.w, plainText(fmt.Sprintln(a...)))
+}
+
+// Essentialf writes a line that is shown even in quiet mode, where emoji and
+// leading blank lines are stripped.
+func (o *output) Essentialf(format string, a ...interface{}) {
+	s := fmt.Sprintf(format, a...)
+	if o.quiet {
+		s = strings.TrimLeft(emojiRe.ReplaceAllString(s, ""), "\n")
+	}
+	fmt.Fprint(o.w, plainText(s))
+}
+
+// progress shows a "label done/total..." counter on a single stderr line
+// while a long operation runs. A nil *progress is valid and shows nothing.
+type progress struct {
+	mu    sync.Mutex
+	label string
+	done  int
+	total int
+}
+
+// newProgress returns a progress counter, or nil when it should not be shown:
+// in quiet or plain mode, when disabled (e.g. for JSON output) or when stderr
+// is not a terminal.
+func newProgress(label string, enabled bool) *progress {
+	if !enabled || quiet || plain || !term.IsTerminal(int(os.Stderr.Fd())) {
+		return nil
+	}
+	return &progress{label: label}
+}
+
+// start resets the counter to 0 of total.
+func (p *progress) start(total int) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.done, p.total = 0, total
+	if total > 0 {
+		p.render()
+	}
+}
+
+// add advances the counter by n.
+func (p *progress) add(n int) {
+	if p == nil || n == 0 {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.done += n
+	p.render()
+}
+
+// finish clears the progress line.
+func (p *progress) finish() {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	fmt.Fprint(os.Stderr, "\r\033[K")
+}
+
+func (p *progress) render() {
+	fmt.Fprintf(os.Stderr, "\r\033[K%s %d/%d...", p.label, p.done, p.total)
+}
@@ -0,0 +1,54 @@
+package cli
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"regexp"
+
+	"github.com/cli/go-gh/pkg/api"
+)
+
+// linkNextRe matches the URL of the next page in a Link header, e.g.
+// <https://api.github.com/repositories/1/pulls?page=2>; rel="next".
+var linkNextRe = regexp.MustCompile(`<([^>]+)>;\s*rel="next"`)
+
+// restGetPages GETs path and then each page the Link header of the previous
+// response names as the next one, so list endpoints aren't cut off after
+// their first page. page is called with the JSON body of every response and
+// stops the paging by returning false.
+func restGetPages(ctx context.Context, client api.RESTClient, path string, page func(body []byte) (bool, error)) error {
+	for path != "" {
+		var resp *http.Response
+		err := withRetry(ctx, func() error {
+			explainf("+ GET %s", path)
+			var err error
+			resp, err = client.RequestWithContext(ctx, "GET", path, nil)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return err
+		}
+
+		more, err := page(body)
+		if err != nil || !more {
+			return err
+		}
+		path = nextPage(resp.Header.Get("Link"))
+	}
+	return nil
+}
+
+// nextPage returns the URL of the next page named in a Link header, or ""
+// on the last page.
+func nextPage(link string) string {
+	if m := linkNextRe.FindStringSubmatch(link); m != nil {
+		return m[1]
+	}
+	return ""
+}
@@ -0,0 +1,116 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/cli/go-gh/pkg/repository"
+)
+
+func TestNextPage(t *testing.T) {
+	tests := []struct {
+		link string
+		want string
+	}{
+		{"", ""},
+		{`<https://api.github.com/repositories/1/pulls?page=2>; rel="next", <https://api.github.com/repositories/1/pulls?page=5>; rel="last"`, "https://api.github.com/repositories/1/pulls?page=2"},
+		{`<https://api.github.com/repositories/1/pulls?page=1>; rel="prev", <https://api.github.com/repositories/1/pulls?page=3>; rel="next"`, "https://api.github.com/repositories/1/pulls?page=3"},
+		{`<https://api.github.com/repositories/1/pulls?page=1>; rel="first", <https://api.github.com/repositories/1/pulls?page=4>; rel="prev"`, ""},
+	}
+	for _, tt := range tests {
+		if got := nextPage(tt.link); got != tt.want {
+			t.Errorf("nextPage(%q) = %q, want %q", tt.link, got, tt.want)
+		}
+	}
+}
+
+// pagedPulls serves pages of pulls, each linking to the next one, and
+// records the pages requested.
+func pagedPulls(t *testing.T, pages [][]map[string]interface{}) *[]int {
+	requested := &[]int{}
+	fakeAPI(t, func(req *http.Request) (*http.Response, error) {
+		page := 1
+		if p := req.URL.Query().Get("page"); p != "" {
+			page, _ = strconv.Atoi(p)
+		}
+		*requested = append(*requested, page)
+		header := http.Header{}
+		if page < len(pages) {
+			next := *req.URL
+			q := next.Query()
+			q.Set("page", strconv.Itoa(page+1))
+			next.RawQuery = q.Encode()
+			header.Set("Link", fmt.Sprintf(`<%s>; rel="next", <https://api.github.com/x?page=%d>; rel="last"`, next.String(), len(pages)))
+		}
+		body, _ := json.Marshal(pages[page-1])
+		return jsonResponse(req, 200, string(body), header), nil
+	})
+	return requested
+}
+
+func TestRestGetPages(t *testing.T) {
+	requested := pagedPulls(t, [][]map[string]interface{}{
+		{{"number": 1}, {"number": 2}},
+		{{"number": 3}},
+		{{"number": 4}},
+	})
+	repo, _ := repository.Parse("acme/app")
+	client, err := restClient(repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var numbers []int
+	err = restGetPages(context.Background(), client, "repos/acme/app/pulls", func(body []byte) (bool, error) {
+		var page []struct{ Number int }
+		if err := json.Unmarshal(body, &page); err != nil {
+			return false, err
+		}
+		for _, pr := range page {
+			numbers = append(numbers, pr.Number)
+		}
+		return true, nil
+	})
+	if err != nil {
+		t.Fatalf("restGetPages() error = %v", err)
+	}
+	if !equalInts(numbers, []int{1, 2, 3, 4}) || !equalInts(*requested, []int{1, 2, 3}) {
+		t.Errorf("restGetPages() read %v from pages %v, want [1 2 3 4] from [1 2 3]", numbers, *requested)
+	}
+
+	// Returning false stops the paging
+	*requested = nil
+	err = restGetPages(context.Background(), client, "repos/acme/app/pulls", func(body []byte) (bool, error) {
+		return false, nil
+	})
+	if err != nil || !equalInts(*requested, []int{1}) {
+		t.Errorf("restGetPages() stopped after pages %v with error %v, want [1] and nil", *requested, err)
+	}
+}
+
+func TestFindPRForBranchPaged(t *testing.T) {
+	closed := func(n int) map[string]interface{} {
+		return map[string]interface{}{"number": n, "state": "closed", "user": map[string]string{"login": "octocat"}}
+	}
+	requested := pagedPulls(t, [][]map[string]interface{}{
+		{closed(30), closed(29)},
+		{closed(28)},
+		{{"number": 12, "state": "open", "draft": true, "user": map[string]string{"login": "hubot"}}},
+	})
+	repo, _ := repository.Parse("acme/app")
+
+	number, info, err := findPRForBranch(context.Background(), repo, "feature", "all")
+	if err != nil {
+		t.Fatalf("findPRForBranch() error = %v", err)
+	}
+	if number != 12 || info.Status != "open" || !info.Draft || info.Author != "hubot" {
+		t.Errorf("findPRForBranch() = #%d %+v, want the open draft #12 by hubot", number, info)
+	}
+	if !equalInts(*requested, []int{1, 2, 3}) {
+		t.Errorf("requested pages %v, want [1 2 3]", *requested)
+	}
+}
@@ -0,0 +1,64 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/eikster-dk/gh-worktree/internal/worktree"
+	"github.com/spf13/cobra"
+)
+
+func NewPath() *cobra.Command {
+	var printCd bool
+
+	cmd := &cobra.Command{
+		Use:   "path [<branch | pr-number>]",
+		Short: "Print the path of the worktree for a branch or PR number",
+		Long: `Prints only the absolute path of the worktree so it can be used in shell substitution.
+Without an argument the root of the worktree containing the current directory is printed.
+A subprocess cannot change the directory of your shell, so use it as:
+
+  cd "$(gh worktree path 1234)"
+  eval "$(gh worktree path feature-x --print-cd)"`,
+		Example:           `cd "$(gh worktree path 1234)"`,
+		SilenceUsage:      true,
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: completeWorktreeBranches,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var path string
+			if len(args) > 0 {
+				var err error
+				if path, err = resolveWorktreePath(cmd.Context(), args[0]); err != nil {
+					return err
+				}
+			} else {
+				wt, err := currentWorktree(cmd.Context())
+				if err != nil {
+					return err
+				}
+				path = wt.Path
+			}
+
+			path, err := worktree.AbsPath(path)
+			if err != nil {
+				return err
+			}
+
+			if printCd {
+				fmt.Printf("cd %s\n", shellQuote(path))
+				return nil
+			}
+			fmt.Println(path)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&printCd, "print-cd", false, "Print a cd command suitable for eval")
+
+	return cmd
+}
+
+// shellQuote wraps s in single quotes for POSIX shells.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
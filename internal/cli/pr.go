@@ -5,13 +5,13 @@ import (
 	"fmt"
 	"strconv"
 
-	gh "github.com/cli/go-gh"
 	"github.com/eikster-dk/gh-worktree/internal/worktree"
 	"github.com/spf13/cobra"
 )
 
 func NewPr() *cobra.Command {
 	var appendBranch bool
+	var slugify bool
 
 	cmd := &cobra.Command{
 		Use:     "pr [number] [path]",
@@ -40,11 +40,18 @@ func NewPr() *cobra.Command {
 				return err
 			}
 
-			return worktree.AddWithOptions(branch, path, appendBranch)
+			worktreePath, err := worktree.AddWithOptions(cmd.Context(), branch, worktree.Options{Path: path, AppendBranch: appendBranch, Slugify: slugify})
+			if err != nil {
+				return err
+			}
+
+			fmt.Println(worktreePath)
+			return nil
 		},
 	}
 
 	cmd.Flags().BoolVar(&appendBranch, "append-branch", false, "Append branch name as subdirectory to the provided path")
+	cmd.Flags().BoolVar(&slugify, "slugify", false, "Name the directory feature-x instead of nesting feature/x for branches with slashes")
 
 	return cmd
 }
@@ -56,12 +63,12 @@ func findByNumber(number int64) (string, error) {
 		}
 	}
 
-	repo, err := gh.CurrentRepository()
+	repo, err := currentRepository()
 	if err != nil {
 		return "", fmt.Errorf("could not get current repository: %w", err)
 	}
 
-	restApi, err := gh.RESTClient(nil)
+	restApi, err := restClient(repo)
 	if err != nil {
 		return "", fmt.Errorf("could not get gh rest client: %w", err)
 	}
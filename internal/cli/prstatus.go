@@ -0,0 +1,483 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/cli/go-gh/pkg/api"
+	"github.com/cli/go-gh/pkg/repository"
+)
+
+// prInfo is what we know about a PR. The review details are only known when
+// the PR was looked up with GraphQL; the REST fallback leaves ReviewDecision
+// and Checks empty.
+type prInfo struct {
+	Status         string `json:"status"` // "open", "merged" or "closed"
+	Draft          bool   `json:"draft,omitempty"`
+	Mergeable      string `json:"mergeable,omitempty"`      // "mergeable", "conflicting" or "unknown"
+	ReviewDecision string `json:"reviewDecision,omitempty"` // "approved", "changes_requested" or "review_required"
+	Checks         string `json:"checks,omitempty"`         // "success", "failure", "pending", "error" or "expected"
+	Author         string `json:"author,omitempty"`         // login of the PR author
+}
+
+func getPRStatus(ctx context.Context, repo repository.Repository, prNumber int) (prInfo, error) {
+	client, err := restClient(repo)
+	if err != nil {
+		return prInfo{}, err
+	}
+
+	var pr struct {
+		State     string
+		Merged    bool  `json:"merged"`
+		Draft     bool  `json:"draft"`
+		Mergeable *bool `json:"mergeable"`
+		User      struct {
+			Login string
+		}
+	}
+
+	path := fmt.Sprintf("repos/%s/%s/pulls/%d", repo.Owner(), repo.Name(), prNumber)
+	err = withRetry(ctx, func() error {
+		explainf("+ GET %s", path)
+		return client.DoWithContext(ctx, "GET", path, nil, &pr)
+	})
+	if err != nil {
+		return prInfo{}, err
+	}
+
+	if pr.Merged {
+		return prInfo{Status: "merged", Draft: pr.Draft, Author: pr.User.Login}, nil
+	}
+	info := prInfo{Status: pr.State, Draft: pr.Draft, Author: pr.User.Login} // "open" or "closed"
+	if pr.Mergeable != nil {
+		info.Mergeable = "conflicting"
+		if *pr.Mergeable {
+			info.Mergeable = "mergeable"
+		}
+	}
+	return info, nil
+}
+
+// getPRStatusesBatch looks up the status of all given PRs with a single
+// GraphQL query. The returned map is keyed by PR number. PRs that don't exist
+// are left out. An error about a single PR doesn't fail the others: such PRs
+// are returned as failed, to be looked up another way.
+func getPRStatusesBatch(ctx context.Context, repo repository.Repository, prNumbers []int) (map[int]prInfo, []int, error) {
+	client, err := gqlClient(repo)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var fields strings.Builder
+	for _, n := range prNumbers {
+		fmt.Fprintf(&fields, "pr%d: pullRequest(number: %d) { state isDraft mergeable reviewDecision author { login } commits(last: 1) { nodes { commit { statusCheckRollup { state } } } } }\n", n, n)
+	}
+	query := fmt.Sprintf("query($owner: String!, $name: String!) { repository(owner: $owner, name: $name) {\n%s} }", fields.String())
+
+	var resp struct {
+		Repository map[string]*struct {
+			State          string
+			IsDraft        bool
+			Mergeable      string
+			ReviewDecision string
+			Author         *struct {
+				Login string
+			}
+			Commits struct {
+				Nodes []struct {
+					Commit struct {
+						StatusCheckRollup *struct {
+							State string
+						}
+					}
+				}
+			}
+		}
+	}
+	variables := map[string]interface{}{"owner": repo.Owner(), "name": repo.Name()}
+	explainf("+ POST graphql: pull requests %s of %s/%s", formatPRNumbers(prNumbers), repo.Owner(), repo.Name())
+	err = client.DoWithContext(ctx, query, variables, &resp)
+	var gqlErr api.GQLError
+	if err != nil && (!errors.As(err, &gqlErr) || resp.Repository == nil) {
+		return nil, nil, err
+	}
+
+	// Errors about single PRs, e.g. NOT_FOUND for a number that does not
+	// exist, come with the data of the others
+	aliasErrs := map[string]api.GQLErrorItem{}
+	for _, e := range gqlErr.Errors {
+		alias := errorAlias(e)
+		if alias == "" {
+			return nil, nil, err
+		}
+		aliasErrs[alias] = e
+	}
+
+	statuses := make(map[int]prInfo, len(prNumbers))
+	var failed []int
+	for _, n := range prNumbers {
+		alias := fmt.Sprintf("pr%d", n)
+		if e, ok := aliasErrs[alias]; ok {
+			if e.Type == "NOT_FOUND" {
+				logf(levelInfo, "%s/%s#%d does not exist", repo.Owner(), repo.Name(), n)
+			} else {
+				logf(levelInfo, "%s/%s#%d: %s", repo.Owner(), repo.Name(), n, e.Message)
+				failed = append(failed, n)
+			}
+			continue
+		}
+		if pr := resp.Repository[alias]; pr != nil {
+			info := prInfo{
+				Status:         strings.ToLower(pr.State), // "open", "closed" or "merged"
+				Draft:          pr.IsDraft,
+				Mergeable:      strings.ToLower(pr.Mergeable),
+				ReviewDecision: strings.ToLower(pr.ReviewDecision),
+			}
+			// The author is null for deleted accounts
+			if pr.Author != nil {
+				info.Author = pr.Author.Login
+			}
+			if len(pr.Commits.Nodes) > 0 && pr.Commits.Nodes[0].Commit.StatusCheckRollup != nil {
+				info.Checks = strings.ToLower(pr.Commits.Nodes[0].Commit.StatusCheckRollup.State)
+			}
+			statuses[n] = info
+		}
+	}
+	return statuses, failed, nil
+}
+
+// errorAlias returns the pullRequest alias, e.g. pr12, a GraphQL error of
+// getPRStatusesBatch is about, or "" when it is about the query as a whole.
+func errorAlias(e api.GQLErrorItem) string {
+	if len(e.Path) < 2 || e.Path[0] != "repository" {
+		return ""
+	}
+	alias, _ := e.Path[1].(string)
+	return alias
+}
+
+// formatPRNumbers renders PR numbers as a comma separated list of #N.
+func formatPRNumbers(prNumbers []int) string {
+	parts := make([]string, len(prNumbers))
+	for i, n := range prNumbers {
+		parts[i] = fmt.Sprintf("#%d", n)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// concurrency bounds the number of concurrent operations, such as PR status
+// lookups. It is set by the global --concurrency flag.
+var concurrency = 8
+
+// getPRStatusesREST looks up the status of all given PRs with one REST call
+// each, spread across a bounded worker pool. PRs whose lookup failed are
+// missing from the returned map. Every PR looked up advances p, whether it
+// was found or not.
+func getPRStatusesREST(ctx context.Context, repo repository.Repository, prNumbers []int, p *progress) map[int]prInfo {
+	statuses := make(map[int]prInfo, len(prNumbers))
+	var mu sync.Mutex
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for n := range jobs {
+				status, err := getPRStatus(ctx, repo, n)
+				p.add(1)
+				if isNotFound(err) {
+					logf(levelInfo, "%s/%s#%d does not exist", repo.Owner(), repo.Name(), n)
+					continue
+				}
+				if err != nil {
+					logf(levelInfo, "could not get the status of %s/%s#%d: %v", repo.Owner(), repo.Name(), n, err)
+					continue
+				}
+				mu.Lock()
+				statuses[n] = status
+				mu.Unlock()
+			}
+		}()
+	}
+
+dispatch:
+	for _, n := range prNumbers {
+		select {
+		case jobs <- n:
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	return statuses
+}
+
+// fetchPRStatuses looks up the PR status of every worktree with a PR number
+// and writes it back onto the worktree, preserving the slice order. Each
+// repository is tried in turn for the PRs not found in the previous ones.
+// Failed lookups leave PRStatus empty. Statuses are read from and written to
+// cache unless it is nil. Progress is reported to p, which may be nil, and
+// counts the PRs looked up in each repository in turn.
+func fetchPRStatuses(ctx context.Context, repos []repository.Repository, worktrees []WorktreeInfo, cache *prStatusCache, p *progress) {
+	defer p.finish()
+
+	for _, repo := range repos {
+		if ctx.Err() != nil {
+			break
+		}
+		fetchPRStatusesFrom(ctx, repo, worktrees, cache, p)
+	}
+
+	if cache != nil {
+		_ = cache.save()
+	}
+}
+
+// fetchPRAuthors looks up the author of the worktree PRs whose status came
+// from a cache entry written before authors were recorded, with one GraphQL
+// query per repository on host. Cache entries are updated unless cache is
+// nil.
+func fetchPRAuthors(ctx context.Context, host string, worktrees []WorktreeInfo, cache *prStatusCache) {
+	missing := map[string][]int{}
+	var repoNames []string
+	for _, wt := range worktrees {
+		if wt.PRNumber == 0 || wt.PRStatus == "" || wt.PRAuthor != "" || wt.PRRepo == "" {
+			continue
+		}
+		if _, ok := missing[wt.PRRepo]; !ok {
+			repoNames = append(repoNames, wt.PRRepo)
+		}
+		missing[wt.PRRepo] = append(missing[wt.PRRepo], wt.PRNumber)
+	}
+	if len(repoNames) == 0 {
+		return
+	}
+	if err := checkAPI(host); err != nil {
+		logf(levelInfo, "not looking up PR authors: %v", err)
+		return
+	}
+
+	for _, repoName := range repoNames {
+		if ctx.Err() != nil {
+			return
+		}
+		repo, err := repository.ParseWithHost(repoName, host)
+		if err != nil {
+			continue
+		}
+		statuses, _, err := getPRStatusesBatch(ctx, repo, missing[repoName])
+		if err != nil {
+			logf(levelInfo, "could not look up the PR authors in %s: %v", repoName, err)
+			continue
+		}
+		for i := range worktrees {
+			if info, ok := statuses[worktrees[i].PRNumber]; ok && worktrees[i].PRRepo == repoName {
+				worktrees[i].setPR(repoName, info)
+			}
+		}
+		if cache != nil {
+			for n, info := range statuses {
+				cache.set(repo, n, info)
+			}
+		}
+	}
+
+	if cache != nil {
+		_ = cache.save()
+	}
+}
+
+// fetchPRStatusesFrom looks up the worktrees without a PR status in repo. All
+// PRs are queried in one GraphQL request, falling back to per-PR REST calls
+// for the PRs it failed for, or for all of them if it failed as a whole. When
+// repo can't be found because it was renamed or transferred, its new
+// location is used instead. p counts every distinct PR number, found or not.
+func fetchPRStatusesFrom(ctx context.Context, repo repository.Repository, worktrees []WorktreeInfo, cache *prStatusCache, p *progress) {
+	if cache != nil {
+		if moved, ok := cache.getMoved(repo); ok {
+			explainf("  cached: %s/%s moved to %s/%s", repo.Owner(), repo.Name(), moved.Owner(), moved.Name())
+			repo = moved
+		}
+	}
+	repoName := repo.Owner() + "/" + repo.Name()
+
+	var pending []int
+	cached := 0
+	seen := map[int]bool{}
+	for i := range worktrees {
+		n := worktrees[i].PRNumber
+		if n == 0 || worktrees[i].PRStatus != "" {
+			continue
+		}
+		if cache != nil {
+			if info, ok := cache.get(repo, n); ok {
+				explainf("  cached: %s#%d is %s", repoName, n, info.Status)
+				worktrees[i].setPR(repoName, info)
+				if !seen[n] {
+					seen[n] = true
+					cached++
+				}
+				continue
+			}
+		}
+		if !seen[n] {
+			seen[n] = true
+			pending = append(pending, n)
+		}
+	}
+	p.start(cached + len(pending))
+	p.add(cached)
+	if len(pending) == 0 {
+		return
+	}
+	if err := checkAPI(repo.Host()); err != nil {
+		logf(levelInfo, "not looking up %s in %s: %v", formatPRNumbers(pending), repoName, err)
+		return
+	}
+
+	statuses, failed, err := getPRStatusesBatch(ctx, repo, pending)
+	if isNotFound(err) {
+		if moved := followMove(ctx, repo, cache); moved != repo {
+			repo, repoName = moved, moved.Owner()+"/"+moved.Name()
+			statuses, failed, err = getPRStatusesBatch(ctx, repo, pending)
+		}
+	}
+	// PRs looked up with REST advance the progress themselves
+	if err != nil {
+		logf(levelInfo, "batch PR status query in %s failed, falling back to one request per PR: %v", repoName, err)
+		statuses = getPRStatusesREST(ctx, repo, pending, p)
+	} else {
+		p.add(len(pending) - len(failed))
+		if len(failed) > 0 {
+			logf(levelInfo, "batch PR status query in %s failed for %s, falling back to one request each", repoName, formatPRNumbers(failed))
+			for n, info := range getPRStatusesREST(ctx, repo, failed, p) {
+				statuses[n] = info
+			}
+		}
+	}
+
+	for i := range worktrees {
+		if info, ok := statuses[worktrees[i].PRNumber]; ok && worktrees[i].PRStatus == "" {
+			worktrees[i].setPR(repoName, info)
+		}
+	}
+
+	if cache != nil {
+		for n, info := range statuses {
+			cache.set(repo, n, info)
+		}
+	}
+}
+
+// findPRForBranch returns the number and status of the PR in repo whose head
+// is branch, or 0 if there is none: the open one when there is one, the most
+// recent one otherwise. state is "open", "closed" or "all".
+// Branches checked out from forks by add-pr are named OWNER/BRANCH and are
+// looked up with that owner.
+func findPRForBranch(ctx context.Context, repo repository.Repository, branch string, state string) (int, prInfo, error) {
+	client, err := restClient(repo)
+	if err != nil {
+		return 0, prInfo{}, err
+	}
+
+	heads := []string{repo.Owner() + ":" + branch}
+	if owner, ref, ok := strings.Cut(branch, "/"); ok {
+		heads = append(heads, owner+":"+ref)
+	}
+
+	type pullRequest struct {
+		Number   int
+		State    string
+		Draft    bool
+		MergedAt *string `json:"merged_at"`
+		User     struct {
+			Login string
+		}
+	}
+	for _, head := range heads {
+		// Pulls are listed newest first, and all pages are read since an
+		// open PR may follow several closed ones
+		var prs []pullRequest
+		path := fmt.Sprintf("repos/%s/%s/pulls?head=%s&state=%s&per_page=100", repo.Owner(), repo.Name(), url.QueryEscape(head), state)
+		err := restGetPages(ctx, client, path, func(body []byte) (bool, error) {
+			var page []pullRequest
+			if err := json.Unmarshal(body, &page); err != nil {
+				return false, err
+			}
+			prs = append(prs, page...)
+			return true, nil
+		})
+		if err != nil {
+			return 0, prInfo{}, err
+		}
+		if len(prs) == 0 {
+			continue
+		}
+
+		pr := prs[0]
+		for _, p := range prs {
+			if p.State == "open" {
+				pr = p
+				break
+			}
+		}
+		info := prInfo{Status: pr.State, Draft: pr.Draft, Author: pr.User.Login}
+		if pr.MergedAt != nil {
+			info.Status = "merged"
+		}
+		return pr.Number, info, nil
+	}
+	return 0, prInfo{}, nil
+}
+
+// resolvePRNumbers looks up the PR of every worktree whose branch and path
+// name carry no PR number, by searching each repository in turn for a PR
+// with the branch as its head. Found PRs are recorded with their status.
+// Lookups are read from and written to cache unless it is nil.
+func resolvePRNumbers(ctx context.Context, repos []repository.Repository, worktrees []WorktreeInfo, cache *prStatusCache) {
+	for i := range worktrees {
+		wt := &worktrees[i]
+		if wt.PRNumber != 0 || wt.Branch == "" {
+			continue
+		}
+
+		for _, repo := range repos {
+			if ctx.Err() != nil {
+				return
+			}
+
+			number, info, ok := 0, prInfo{}, false
+			if cache != nil {
+				number, info, ok = cache.getBranch(repo, wt.Branch)
+			}
+			if !ok && checkAPI(repo.Host()) != nil {
+				continue
+			}
+			if !ok {
+				var err error
+				number, info, err = findPRForBranch(ctx, repo, wt.Branch, "all")
+				if err != nil {
+					logf(levelInfo, "could not look up the PR for branch %s in %s/%s: %v", wt.Branch, repo.Owner(), repo.Name(), err)
+					continue
+				}
+				if cache != nil {
+					cache.setBranch(repo, wt.Branch, number, info)
+				}
+			}
+
+			if number != 0 {
+				wt.PRNumber = number
+				wt.setPR(repo.Owner()+"/"+repo.Name(), info)
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package cli
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/cli/go-gh/pkg/repository"
+)
+
+func TestFetchPRStatusesPartialBatch(t *testing.T) {
+	var mu sync.Mutex
+	var requests []string
+	fakeAPI(t, func(req *http.Request) (*http.Response, error) {
+		mu.Lock()
+		requests = append(requests, req.Method+" "+req.URL.Path)
+		mu.Unlock()
+		switch req.URL.Path {
+		case "/graphql":
+			return jsonResponse(req, 200, `{
+				"data": {"repository": {"pr12": {"state": "OPEN"}, "pr99": null, "pr5": null}},
+				"errors": [
+					{"type": "NOT_FOUND", "path": ["repository", "pr99"], "message": "Could not resolve to a PullRequest with the number of 99."},
+					{"type": "FORBIDDEN", "path": ["repository", "pr5"], "message": "Resource not accessible by integration"}
+				]
+			}`, nil), nil
+		case "/repos/acme/app/pulls/5":
+			return jsonResponse(req, 200, `{"state":"closed","merged":true}`, nil), nil
+		}
+		return jsonResponse(req, 404, `{"message":"Not Found"}`, nil), nil
+	})
+
+	repo, _ := repository.Parse("acme/app")
+	worktrees := []WorktreeInfo{{PRNumber: 12}, {PRNumber: 99}, {PRNumber: 5}}
+	p := &progress{label: "Checking PR status"}
+	fetchPRStatuses(context.Background(), []repository.Repository{repo}, worktrees, nil, p)
+
+	want := []string{"open", "", "merged"}
+	for i, wt := range worktrees {
+		if wt.PRStatus != want[i] {
+			t.Errorf("status of #%d = %q, want %q", wt.PRNumber, wt.PRStatus, want[i])
+		}
+	}
+	// Only the PR the batch failed for is looked up again; the one that does
+	// not exist is neither looked up again nor a reason to look for a moved
+	// repository
+	if p.done != 3 || p.total != 3 {
+		t.Errorf("progress = %d/%d, want 3/3", p.done, p.total)
+	}
+	sort.Strings(requests)
+	if got, want := strings.Join(requests, ", "), "GET /repos/acme/app/pulls/5, POST /graphql"; got != want {
+		t.Errorf("requests = %s, want %s", got, want)
+	}
+}
+
+func TestFetchPRStatusesProgressCountsFailures(t *testing.T) {
+	fakeAPI(t, func(req *http.Request) (*http.Response, error) {
+		switch req.URL.Path {
+		case "/graphql":
+			return jsonResponse(req, 200, `{"errors": [{"message": "Something went wrong"}]}`, nil), nil
+		case "/repos/acme/app/pulls/12":
+			return jsonResponse(req, 200, `{"state":"open"}`, nil), nil
+		}
+		return jsonResponse(req, 404, `{"message":"Not Found"}`, nil), nil
+	})
+
+	repo, _ := repository.Parse("acme/app")
+	worktrees := []WorktreeInfo{{PRNumber: 12}, {PRNumber: 99}, {PRNumber: 5}, {PRNumber: 12}}
+	p := &progress{label: "Checking PR status"}
+	fetchPRStatuses(context.Background(), []repository.Repository{repo}, worktrees, nil, p)
+
+	if worktrees[0].PRStatus != "open" || worktrees[3].PRStatus != "open" {
+		t.Errorf("statuses of #12 = %q, %q, want open", worktrees[0].PRStatus, worktrees[3].PRStatus)
+	}
+	// Each distinct PR counts once, whether its lookup succeeded or not
+	if p.done != 3 || p.total != 3 {
+		t.Errorf("progress = %d/%d, want 3/3", p.done, p.total)
+	}
+}
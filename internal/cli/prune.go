@@ -0,0 +1,78 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+func NewPrune() *cobra.Command {
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "prune",
+		Short: "Prune administrative data for worktrees whose directory no longer exists",
+		Long: `Reports worktrees whose directory has been deleted and runs git worktree prune
+to remove their stale administrative entries.`,
+		Example: "gh worktree prune --dry-run",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out := newOutput(false)
+
+			worktrees, err := getWorktreeInfo(cmd.Context())
+			if err != nil {
+				return fmt.Errorf("failed to get worktree info: %w", err)
+			}
+
+			var missing []WorktreeInfo
+			for _, wt := range worktrees {
+				if _, err := os.Stat(wt.Path); os.IsNotExist(err) {
+					missing = append(missing, wt)
+				}
+			}
+
+			if len(missing) == 0 {
+				out.Println("✨ No worktrees with missing directories found")
+			} else {
+				out.Essentialf("🔍 Found %d worktree(s) whose directory no longer exists:\n\n", len(missing))
+				for _, wt := range missing {
+					out.Printf("  • %s (%s)\n", wt.Path, wt.Branch)
+				}
+				out.Println()
+			}
+
+			output, err := pruneWorktrees(cmd.Context(), dryRun)
+			if err != nil {
+				return fmt.Errorf("failed to prune worktrees: %w\nOutput: %s", err, output)
+			}
+			if output != "" {
+				out.Essentialf("%s\n", output)
+			}
+
+			if dryRun {
+				out.Println("(Dry run - nothing was pruned)")
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show what would be pruned without actually pruning")
+
+	return cmd
+}
+
+func pruneWorktrees(ctx context.Context, dryRun bool) (string, error) {
+	args := []string{"worktree", "prune", "--verbose"}
+	if dryRun {
+		args = append(args, "--dry-run")
+	}
+
+	cmd, err := gitCommand(ctx, args...)
+	if err != nil {
+		return "", err
+	}
+	output, err := cmd.CombinedOutput()
+	return strings.TrimSpace(string(output)), err
+}
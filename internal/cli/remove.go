@@ -0,0 +1,195 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/eikster-dk/gh-worktree/internal/worktree"
+	"github.com/spf13/cobra"
+)
+
+func NewRemove() *cobra.Command {
+	var force bool
+	var olderThan string
+	var yes bool
+
+	cmd := &cobra.Command{
+		Use:   "remove [<branch | #pr-number> | --older-than <age>]",
+		Short: "Remove the worktree for a branch or PR number",
+		Long: `Removes the worktree for a branch or PR number, or the worktree containing the
+current directory when no argument is given. The main worktree can't be
+removed.
+
+With --older-than, removes every worktree whose last commit is older than the
+given age instead, after confirming the list. The main worktree, locked
+worktrees and protected branches (main, master and the protect config option)
+are never removed this way.`,
+		Example: `gh worktree remove feature-x
+gh worktree remove '#123'
+gh worktree remove --older-than 60d`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if olderThan != "" && len(args) > 0 {
+				return errors.New("--older-than cannot be used with a branch name or #pr-number")
+			}
+			return nil
+		},
+		ValidArgsFunction: completeWorktreeBranches,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if olderThan != "" {
+				age, err := parseSince(olderThan)
+				if err != nil {
+					return fmt.Errorf("invalid --older-than: %w", err)
+				}
+				if !cmd.Flags().Changed("yes") {
+					yes = yesFromEnv()
+				}
+				return removeOlderThan(cmd.Context(), age, force, yes)
+			}
+
+			var path string
+			if len(args) > 0 {
+				var err error
+				if path, err = resolveWorktreePath(cmd.Context(), args[0]); err != nil {
+					return err
+				}
+			} else {
+				wt, err := currentWorktree(cmd.Context())
+				if err != nil {
+					return err
+				}
+				if wt.IsMain {
+					cmd.SilenceUsage = true
+					return errors.New("the current directory is in the main worktree, which can't be removed; pass a branch name or #pr-number")
+				}
+				path = wt.Path
+			}
+
+			out := newOutput(false)
+			out.Printf("Removing worktree at %s\n", path)
+			if err := checkRemovable(cmd.Context(), path, force); err != nil {
+				return err
+			}
+
+			if err := removeWorktree(cmd.Context(), path, force); err != nil {
+				return fmt.Errorf("failed to remove worktree: %w", err)
+			}
+
+			out.Essentialf("✅ Removed %s\n", path)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&force, "force", false, "Remove the worktree even if it has uncommitted changes")
+	cmd.Flags().StringVar(&olderThan, "older-than", "", "Remove all worktrees whose last commit is older than this, e.g. 60d, 2w or 72h")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Remove the worktrees matched by --older-than without confirming (or set GH_WORKTREE_YES=1)")
+
+	return cmd
+}
+
+// removeOlderThan removes the worktrees whose last commit is older than age,
+// asking for confirmation first unless yes is set.
+func removeOlderThan(ctx context.Context, age time.Duration, force bool, yes bool) error {
+	out := newOutput(false)
+
+	worktrees, err := getWorktreeInfo(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get worktree info: %w", err)
+	}
+
+	protect := append(defaultProtectedBranches, loadConfig(ctx).Protect...)
+	cutoff := time.Now().Add(-age)
+	var old []WorktreeInfo
+	for _, wt := range worktrees {
+		if wt.IsMain || wt.Bare || wt.Locked || wt.Inaccessible {
+			continue
+		}
+		if isProtectedBranch(wt.Branch, protect) {
+			continue
+		}
+		// Worktrees whose last commit date could not be read are kept
+		if !wt.LastCommit.IsZero() && wt.LastCommit.Before(cutoff) {
+			old = append(old, wt)
+		}
+	}
+
+	if len(old) == 0 {
+		out.Essentialf("✨ No worktrees without commits in %s\n", formatStaleAfter(age))
+		return nil
+	}
+
+	_ = sortWorktrees(old, "age")
+	out.Essentialf("📅 Found %d worktree(s) with no commits in %s:\n\n", len(old), formatStaleAfter(age))
+	for _, wt := range old {
+		out.Essentialf("  • %s (%s, last commit %d days ago)\n", wt.Path, wt.displayBranch(), wt.DaysSinceCommit)
+	}
+
+	if !yes {
+		fmt.Println()
+		if !confirm(os.Stdout, fmt.Sprintf("Remove these %d worktree(s)?", len(old))) {
+			out.Essentialf("Nothing was removed\n")
+			return nil
+		}
+	}
+	out.Println()
+
+	var removed, failed int
+	for _, wt := range old {
+		if err := checkRemovable(ctx, wt.Path, force); err != nil {
+			out.Essentialf("⚠️  Skipped %s: %v\n", wt.Path, err)
+			continue
+		}
+		if err := removeWorktree(ctx, wt.Path, force); err != nil {
+			out.Essentialf("❌ Failed to remove %s: %v\n", wt.Path, err)
+			failed++
+			continue
+		}
+		out.Printf("✅ Removed %s\n", wt.Path)
+		removed++
+	}
+
+	out.Essentialf("\n🏁 Removed %d, skipped %d\n", removed, len(old)-removed-failed)
+	if failed > 0 {
+		return fmt.Errorf("failed to remove %d worktree(s)", failed)
+	}
+	return nil
+}
+
+// resolveWorktreePath finds the worktree path for target, which is either a
+// branch name or a PR number. A PR number may be prefixed with '#'; a bare
+// number is only treated as a PR number when no branch matches it.
+func resolveWorktreePath(ctx context.Context, target string) (string, error) {
+	if strings.HasPrefix(target, "#") {
+		number, err := strconv.Atoi(strings.TrimPrefix(target, "#"))
+		if err != nil {
+			return "", fmt.Errorf("invalid PR number %q", target)
+		}
+		return worktreePathForPR(ctx, number)
+	}
+
+	path, err := worktree.PathForBranch(ctx, target)
+	if err != nil {
+		if number, convErr := strconv.Atoi(target); convErr == nil {
+			return worktreePathForPR(ctx, number)
+		}
+		return "", fmt.Errorf("no worktree found for branch '%s'", target)
+	}
+	return path, nil
+}
+
+func worktreePathForPR(ctx context.Context, number int) (string, error) {
+	worktrees, err := getWorktreeInfo(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to get worktree info: %w", err)
+	}
+	for _, wt := range worktrees {
+		if !wt.IsMain && wt.PRNumber == number {
+			return wt.Path, nil
+		}
+	}
+	return "", fmt.Errorf("no worktree found for PR #%d", number)
+}
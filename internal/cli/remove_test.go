@@ -0,0 +1,34 @@
+package cli
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+)
+
+func TestResolveWorktreePathDetached(t *testing.T) {
+	dir := newTestRepo(t)
+	review := filepath.Join(filepath.Dir(dir), "pr-77-review")
+	runGit(t, dir, "worktree", "add", "-q", "--detach", review)
+
+	for _, target := range []string{"#77", "77", "pr-77-review"} {
+		got, err := resolveWorktreePath(context.Background(), target)
+		if err != nil {
+			t.Errorf("resolveWorktreePath(%q) error = %v", target, err)
+			continue
+		}
+		if got != review {
+			t.Errorf("resolveWorktreePath(%q) = %s, want %s", target, got, review)
+		}
+	}
+
+	worktrees, err := getWorktreeInfo(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, wt := range worktrees {
+		if wt.Path == review && (!wt.Detached || wt.PRNumber != 77) {
+			t.Errorf("review worktree Detached = %v, PRNumber = %d, want true, 77", wt.Detached, wt.PRNumber)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/eikster-dk/gh-worktree/internal/worktree"
+	"github.com/spf13/cobra"
+)
+
+func NewRename() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rename <branch> <new-branch>",
+		Short: "Rename a branch and move its worktree to match",
+		Long: `Renames a branch with git branch -m and moves its worktree to a directory named
+after the new branch. Worktrees created by add-pr keep their PR number prefix.
+A worktree whose directory is not named after the branch stays where it is.`,
+		Example: "gh worktree rename feature-x feature-y",
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(args) < 2 {
+				return errors.New("the branch name and the new branch name are required")
+			}
+
+			return nil
+		},
+		ValidArgsFunction: completeWorktreeBranches,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			oldBranch, newBranch := args[0], args[1]
+
+			source, err := worktree.PathForBranch(cmd.Context(), oldBranch)
+			if err != nil {
+				return err
+			}
+
+			if worktree.BranchExists(cmd.Context(), newBranch) {
+				return fmt.Errorf("branch '%s' already exists", newBranch)
+			}
+
+			dest := renamedWorktreePath(source, oldBranch, newBranch)
+			if dest != source {
+				if _, err := os.Stat(dest); err == nil {
+					return fmt.Errorf("directory already exists at: %s\nPlease remove it or choose a different path", dest)
+				}
+
+				locked, err := isWorktreeLocked(cmd.Context(), source)
+				if err != nil {
+					return err
+				}
+				if locked {
+					return fmt.Errorf("worktree at %s is locked\nUnlock it with 'git worktree unlock' before renaming it", source)
+				}
+			}
+
+			if _, err := gitOutput(cmd.Context(), "branch", "-m", oldBranch, newBranch); err != nil {
+				return fmt.Errorf("failed to rename branch: %w", err)
+			}
+			fmt.Printf("Renamed branch\n  from: %s\n  to:   %s\n", oldBranch, newBranch)
+
+			if dest == source {
+				fmt.Printf("Worktree stays at %s\n", source)
+				return nil
+			}
+
+			if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+				return err
+			}
+			if err := moveWorktree(cmd.Context(), source, dest); err != nil {
+				// Put the branch name back so branch and worktree stay in sync
+				if _, undoErr := gitOutput(cmd.Context(), "branch", "-m", newBranch, oldBranch); undoErr != nil {
+					return fmt.Errorf("%w\nrenaming the branch back to '%s' also failed: %v", err, oldBranch, undoErr)
+				}
+				return err
+			}
+
+			fmt.Printf("Moved worktree\n  from: %s\n  to:   %s\n", source, dest)
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+var prPrefixRe = regexp.MustCompile(`^\d+-`)
+
+// renamedWorktreePath returns where the worktree at path should live once
+// its branch is renamed from oldBranch to newBranch. Directories named after
+// the branch (as add and pr create them) or after its slug with a PR number
+// prefix (as add-pr creates them) follow the new name. Any other path is
+// returned unchanged.
+func renamedWorktreePath(path string, oldBranch string, newBranch string) string {
+	if suffix := string(filepath.Separator) + worktree.NormalizePath(oldBranch); strings.HasSuffix(path, suffix) {
+		return strings.TrimSuffix(path, suffix) + string(filepath.Separator) + filepath.FromSlash(newBranch)
+	}
+
+	dir, base := filepath.Split(path)
+	prefix := prPrefixRe.FindString(base)
+	if strings.TrimPrefix(base, prefix) == worktree.Slug(oldBranch) {
+		return filepath.Join(dir, prefix+worktree.Slug(newBranch))
+	}
+	return path
+}
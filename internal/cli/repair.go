@@ -0,0 +1,95 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+func NewRepair() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "repair [<path>...]",
+		Short: "Repair worktree links after the repository or a worktree was moved",
+		Long: `Runs git worktree repair and reports which worktree links were fixed.
+
+Run it from the main worktree after moving the repository directory. When
+worktrees were moved by hand, pass their new paths so git can find them again.`,
+		Example: `gh worktree repair
+gh worktree repair ../feature-x ../feature-y`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out := newOutput(false)
+
+			output, err := repairWorktrees(cmd.Context(), args)
+			repaired, failed := parseRepairOutput(output)
+			if err != nil && len(failed) == 0 {
+				return fmt.Errorf("failed to repair worktrees: %w\nOutput: %s", err, output)
+			}
+
+			if len(repaired) == 0 && len(failed) == 0 {
+				out.Println("✨ All worktree links are intact")
+				return nil
+			}
+
+			if len(repaired) > 0 {
+				out.Essentialf("🔧 Repaired %d worktree link(s):\n\n", len(repaired))
+				for _, r := range repaired {
+					out.Printf("  • %s (%s)\n", r.path, r.reason)
+				}
+				out.Println()
+			}
+
+			if len(failed) > 0 {
+				out.Essentialf("⚠️  Could not repair %d worktree link(s):\n\n", len(failed))
+				for _, r := range failed {
+					out.Printf("  • %s (%s)\n", r.path, r.reason)
+				}
+				out.Println()
+				return fmt.Errorf("failed to repair %d worktree link(s)", len(failed))
+			}
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+// repairResult is one line reported by git worktree repair.
+type repairResult struct {
+	path   string
+	reason string
+}
+
+func repairWorktrees(ctx context.Context, paths []string) (string, error) {
+	cmd, err := gitCommand(ctx, append([]string{"worktree", "repair"}, paths...)...)
+	if err != nil {
+		return "", err
+	}
+	output, err := cmd.CombinedOutput()
+	return strings.TrimSpace(string(output)), err
+}
+
+// parseRepairOutput splits the output of git worktree repair into the links
+// it fixed ("repair: <reason>: <path>") and the ones it could not fix
+// ("error: <reason>: <path>").
+func parseRepairOutput(output string) (repaired []repairResult, failed []repairResult) {
+	for _, line := range strings.Split(output, "\n") {
+		var list *[]repairResult
+		switch {
+		case strings.HasPrefix(line, "repair: "):
+			list, line = &repaired, strings.TrimPrefix(line, "repair: ")
+		case strings.HasPrefix(line, "error: "):
+			list, line = &failed, strings.TrimPrefix(line, "error: ")
+		default:
+			continue
+		}
+
+		result := repairResult{reason: line}
+		if i := strings.LastIndex(line, ": "); i >= 0 {
+			result = repairResult{path: line[i+2:], reason: line[:i]}
+		}
+		*list = append(*list, result)
+	}
+	return repaired, failed
+}
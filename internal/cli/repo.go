@@ -0,0 +1,226 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+
+	gh "github.com/cli/go-gh"
+	"github.com/cli/go-gh/pkg/api"
+	"github.com/cli/go-gh/pkg/auth"
+	"github.com/cli/go-gh/pkg/repository"
+	"github.com/eikster-dk/gh-worktree/internal/worktree"
+)
+
+// repoOverride is set by the global --repo flag.
+var repoOverride string
+
+// hostnameOverride is set by the global --hostname flag.
+var hostnameOverride string
+
+// currentRepository returns the repository selected with --repo, or the
+// repository the current directory is tracking.
+func currentRepository() (repository.Repository, error) {
+	if repoOverride != "" {
+		return parseRepoOverride()
+	}
+	repo, err := ghCurrentRepository()
+	if err != nil {
+		return nil, err
+	}
+	return withHostname(repo)
+}
+
+// ghCurrentRepository returns the repository the -C directory is tracking.
+// go-gh only looks at the working directory of the process, so it is
+// switched to the -C directory for the lookup.
+func ghCurrentRepository() (repository.Repository, error) {
+	if worktree.Dir == "" {
+		return gh.CurrentRepository()
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chdir(worktree.Dir); err != nil {
+		return nil, err
+	}
+	defer os.Chdir(wd)
+	return gh.CurrentRepository()
+}
+
+// parseRepoOverride parses --repo. A repository given without a host is on
+// the --hostname host when that is set.
+func parseRepoOverride() (repository.Repository, error) {
+	if hostnameOverride != "" {
+		return repository.ParseWithHost(repoOverride, hostnameOverride)
+	}
+	return repository.Parse(repoOverride)
+}
+
+// withHostname moves an auto-detected repository to the --hostname host when
+// that is set.
+func withHostname(repo repository.Repository) (repository.Repository, error) {
+	if hostnameOverride == "" {
+		return repo, nil
+	}
+	return repository.ParseWithHost(repo.Owner()+"/"+repo.Name(), hostnameOverride)
+}
+
+// apiOptions returns the API client options for talking to the host repo
+// lives on, so GitHub Enterprise repositories are queried on their own
+// server with that server's token.
+func apiOptions(repo repository.Repository) *api.ClientOptions {
+	return &api.ClientOptions{Host: repo.Host(), Transport: apiTransport}
+}
+
+// apiTransport, when set, replaces the HTTP transport of the API clients,
+// e.g. with a fake GitHub in tests.
+var apiTransport http.RoundTripper
+
+// offline is set by the global --offline flag.
+var offline bool
+
+// errOffline means the GitHub API was not used because of --offline.
+var errOffline = errors.New("GitHub API access is disabled by --offline")
+
+// errNotAuthenticated means gh has no token for the host of a repository.
+var errNotAuthenticated = errors.New("not logged in to GitHub")
+
+var (
+	authMu     sync.Mutex
+	authByHost = map[string]bool{}
+)
+
+// checkAPI returns errOffline or errNotAuthenticated, wrapped with the host
+// and what to do about it, when the GitHub API can't be used for host.
+func checkAPI(host string) error {
+	if offline {
+		return errOffline
+	}
+
+	authMu.Lock()
+	defer authMu.Unlock()
+	ok, checked := authByHost[host]
+	if !checked {
+		token, _ := auth.TokenForHost(host)
+		ok = token != ""
+		authByHost[host] = ok
+	}
+	if !ok {
+		return fmt.Errorf("%w on %s: run `gh auth login --hostname %s`", errNotAuthenticated, host, host)
+	}
+	return nil
+}
+
+// restClient returns a REST client for the host of repo, failing early when
+// the API can't be used (see checkAPI).
+func restClient(repo repository.Repository) (api.RESTClient, error) {
+	if err := checkAPI(repo.Host()); err != nil {
+		return nil, err
+	}
+	return gh.RESTClient(apiOptions(repo))
+}
+
+// gqlClient returns a GraphQL client for the host of repo, failing early
+// when the API can't be used (see checkAPI).
+func gqlClient(repo repository.Repository) (api.GQLClient, error) {
+	if err := checkAPI(repo.Host()); err != nil {
+		return nil, err
+	}
+	return gh.GQLClient(apiOptions(repo))
+}
+
+// resolveRepositories returns the repositories PR numbers are looked up in,
+// in order of preference: the base repository chosen with `gh repo
+// set-default` (or the current repository when none is set), followed by the
+// repository of the upstream remote in fork-based workflows. When --repo is
+// set only that repository is used.
+func resolveRepositories(ctx context.Context) ([]repository.Repository, error) {
+	if repoOverride != "" {
+		repo, err := parseRepoOverride()
+		if err != nil {
+			return nil, err
+		}
+		return []repository.Repository{repo}, nil
+	}
+
+	primary, err := defaultRepository(ctx)
+	if err != nil {
+		primary, err = ghCurrentRepository()
+		if err != nil {
+			return nil, err
+		}
+	}
+	if primary, err = withHostname(primary); err != nil {
+		return nil, err
+	}
+	repos := []repository.Repository{primary}
+
+	if url, err := gitOutput(ctx, "remote", "get-url", "upstream"); err == nil {
+		if upstream, err := repository.Parse(url); err == nil {
+			if upstream, err = withHostname(upstream); err == nil && !sameRepository(primary, upstream) {
+				repos = append(repos, upstream)
+			}
+		}
+	}
+
+	return repos, nil
+}
+
+// defaultRepository returns the repository configured with `gh repo
+// set-default`, which is stored in the gh-resolved key of a remote.
+func defaultRepository(ctx context.Context) (repository.Repository, error) {
+	output, err := gitOutput(ctx, "config", "--get-regexp", `^remote\..*\.gh-resolved$`)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		// remote.<name>.gh-resolved <base | OWNER/REPO>
+		if fields[1] != "base" {
+			return repository.Parse(fields[1])
+		}
+		remote := strings.TrimSuffix(strings.TrimPrefix(fields[0], "remote."), ".gh-resolved")
+		url, err := gitOutput(ctx, "remote", "get-url", remote)
+		if err != nil {
+			return nil, err
+		}
+		return repository.Parse(url)
+	}
+	return nil, errors.New("no default repository configured")
+}
+
+func sameRepository(a, b repository.Repository) bool {
+	return strings.EqualFold(a.Host(), b.Host()) &&
+		strings.EqualFold(a.Owner(), b.Owner()) &&
+		strings.EqualFold(a.Name(), b.Name())
+}
+
+// gitOutput runs git with args and returns its trimmed stdout.
+func gitOutput(ctx context.Context, args ...string) (string, error) {
+	cmd, err := gitCommand(ctx, args...)
+	if err != nil {
+		return "", err
+	}
+
+	output, err := cmd.Output()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return "", fmt.Errorf("%w: %s", err, strings.TrimSpace(string(exitErr.Stderr)))
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
@@ -0,0 +1,99 @@
+package cli
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/cli/go-gh/pkg/repository"
+)
+
+func TestGetPRStatusEnterprise(t *testing.T) {
+	tests := []struct {
+		host     string
+		wantURL  string
+		wantAuth string
+	}{
+		{"ghe.example.com", "https://ghe.example.com/api/v3/repos/acme/app/pulls/12", "token ghe-token"},
+		{"github.com", "https://api.github.com/repos/acme/app/pulls/12", "token dotcom-token"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			var gotURL, gotAuth string
+			fakeAPI(t, func(req *http.Request) (*http.Response, error) {
+				gotURL, gotAuth = req.URL.String(), req.Header.Get("Authorization")
+				return jsonResponse(req, 200, `{"state":"closed","merged":true,"user":{"login":"octocat"}}`, nil), nil
+			})
+
+			repo, err := repository.ParseWithHost("acme/app", tt.host)
+			if err != nil {
+				t.Fatal(err)
+			}
+			info, err := getPRStatus(context.Background(), repo, 12)
+			if err != nil {
+				t.Fatalf("getPRStatus() error = %v", err)
+			}
+			if gotURL != tt.wantURL {
+				t.Errorf("requested %s, want %s", gotURL, tt.wantURL)
+			}
+			if gotAuth != tt.wantAuth {
+				t.Errorf("Authorization = %q, want %q", gotAuth, tt.wantAuth)
+			}
+			if info.Status != "merged" || info.Author != "octocat" {
+				t.Errorf("getPRStatus() = %+v, want merged by octocat", info)
+			}
+		})
+	}
+}
+
+func TestGetPRStatusesBatchEnterprise(t *testing.T) {
+	var gotURL string
+	fakeAPI(t, func(req *http.Request) (*http.Response, error) {
+		gotURL = req.URL.String()
+		return jsonResponse(req, 200, `{"data":{"repository":{"pr12":{"state":"OPEN","isDraft":true}}}}`, nil), nil
+	})
+
+	repo, _ := repository.ParseWithHost("acme/app", "ghe.example.com")
+	statuses, _, err := getPRStatusesBatch(context.Background(), repo, []int{12})
+	if err != nil {
+		t.Fatalf("getPRStatusesBatch() error = %v", err)
+	}
+	if want := "https://ghe.example.com/api/graphql"; gotURL != want {
+		t.Errorf("requested %s, want %s", gotURL, want)
+	}
+	if info := statuses[12]; info.Status != "open" || !info.Draft {
+		t.Errorf("status of #12 = %+v, want an open draft", info)
+	}
+}
+
+func TestHostnameOverride(t *testing.T) {
+	savedRepo, savedHost := repoOverride, hostnameOverride
+	t.Cleanup(func() { repoOverride, hostnameOverride = savedRepo, savedHost })
+
+	tests := []struct {
+		repo     string
+		hostname string
+		want     string
+	}{
+		{"acme/app", "", "github.com"},
+		{"acme/app", "ghe.example.com", "ghe.example.com"},
+		{"ghe.example.com/acme/app", "", "ghe.example.com"},
+	}
+	for _, tt := range tests {
+		repoOverride, hostnameOverride = tt.repo, tt.hostname
+		repo, err := currentRepository()
+		if err != nil {
+			t.Errorf("currentRepository() with --repo %s --hostname %q error = %v", tt.repo, tt.hostname, err)
+			continue
+		}
+		if repo.Host() != tt.want || repo.Owner() != "acme" || repo.Name() != "app" {
+			t.Errorf("currentRepository() with --repo %s --hostname %q = %s/%s/%s, want %s/acme/app", tt.repo, tt.hostname, repo.Host(), repo.Owner(), repo.Name(), tt.want)
+		}
+	}
+
+	repo, _ := repository.Parse("acme/app")
+	hostnameOverride = "ghe.example.com"
+	if moved, err := withHostname(repo); err != nil || moved.Host() != "ghe.example.com" {
+		t.Errorf("withHostname() = %v, %v, want acme/app on ghe.example.com", moved, err)
+	}
+}
@@ -0,0 +1,101 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/cli/go-gh/pkg/api"
+)
+
+const (
+	// retryAttempts is how often a GitHub API request is tried in total.
+	retryAttempts = 4
+	// retryBaseDelay is the wait before the first retry. It doubles with
+	// every further retry.
+	retryBaseDelay = 500 * time.Millisecond
+	// retryMaxDelay caps how long we wait before a retry. When GitHub asks
+	// us to wait longer, e.g. until an hourly rate limit resets, we give up.
+	retryMaxDelay = 30 * time.Second
+)
+
+// withRetry calls fn until it succeeds, fails with an error that is not
+// worth retrying, or retryAttempts is reached. It returns the last error.
+func withRetry(ctx context.Context, fn func() error) error {
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil {
+			return nil
+		}
+
+		delay, ok := retryDelay(err, attempt-1)
+		if !ok {
+			if attempt > 1 {
+				return fmt.Errorf("giving up after %d attempts: %w", attempt, err)
+			}
+			return err
+		}
+		if attempt == retryAttempts {
+			return fmt.Errorf("giving up after %d attempts: %w", attempt, err)
+		}
+		explainf("  retrying in %s: %v", delay, err)
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// retryDelay returns how long to wait before retrying after err, and false
+// when err is not worth retrying. GitHub's Retry-After and
+// X-RateLimit-Reset headers take precedence over exponential backoff.
+func retryDelay(err error, attempt int) (time.Duration, bool) {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return 0, false
+	}
+
+	delay := retryBaseDelay << attempt
+
+	var httpErr api.HTTPError
+	if !errors.As(err, &httpErr) {
+		// Network errors such as a reset connection are usually transient
+		return delay, true
+	}
+	if !isRetryable(httpErr) {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(httpErr.Headers.Get("Retry-After")); err == nil {
+		delay = time.Duration(seconds) * time.Second
+	} else if httpErr.Headers.Get("X-RateLimit-Remaining") == "0" {
+		if reset, err := strconv.ParseInt(httpErr.Headers.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+			delay = time.Until(time.Unix(reset, 0))
+		}
+	}
+
+	if delay > retryMaxDelay {
+		return 0, false
+	}
+	return delay, true
+}
+
+// isRetryable reports whether a GitHub API error is transient: a server
+// error or a rate limit. Other client errors, like a PR that does not
+// exist, will fail the same way again.
+func isRetryable(err api.HTTPError) bool {
+	switch {
+	case err.StatusCode >= 500:
+		return true
+	case err.StatusCode == http.StatusTooManyRequests:
+		return true
+	case err.StatusCode == http.StatusForbidden:
+		// Primary and secondary rate limits are reported as 403
+		return err.Headers.Get("Retry-After") != "" || err.Headers.Get("X-RateLimit-Remaining") == "0"
+	}
+	return false
+}
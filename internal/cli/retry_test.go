@@ -0,0 +1,77 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/cli/go-gh/pkg/api"
+	"github.com/cli/go-gh/pkg/repository"
+)
+
+func TestWithRetry(t *testing.T) {
+	// Retry-After: 0 makes the retries happen without waiting
+	retryNow := http.Header{"Retry-After": []string{"0"}}
+
+	tests := []struct {
+		name       string
+		statuses   []int
+		header     http.Header
+		cancel     bool
+		wantCalls  int
+		wantStatus int
+		wantErr    error
+	}{
+		{name: "502 then success", statuses: []int{502, 200}, header: retryNow, wantCalls: 2},
+		{name: "404 is not retried", statuses: []int{404}, header: retryNow, wantCalls: 1, wantStatus: 404},
+		{name: "gives up after retryAttempts", statuses: []int{502, 502, 502, 502, 200}, header: retryNow, wantCalls: retryAttempts, wantStatus: 502},
+		{name: "cancelled while waiting to retry", statuses: []int{502, 200}, cancel: true, wantCalls: 1, wantErr: context.Canceled},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			calls := 0
+			fakeAPI(t, func(req *http.Request) (*http.Response, error) {
+				status := tt.statuses[calls]
+				calls++
+				if tt.cancel {
+					cancel()
+				}
+				if status == 200 {
+					return jsonResponse(req, status, `{}`, nil), nil
+				}
+				return jsonResponse(req, status, `{"message":"failed"}`, tt.header.Clone()), nil
+			})
+
+			repo, _ := repository.Parse("acme/app")
+			client, err := restClient(repo)
+			if err != nil {
+				t.Fatalf("restClient() error = %v", err)
+			}
+			err = withRetry(ctx, func() error {
+				var resp struct{}
+				return client.DoWithContext(ctx, "GET", "repos/acme/app/pulls/1", nil, &resp)
+			})
+
+			if calls != tt.wantCalls {
+				t.Errorf("%d requests, want %d", calls, tt.wantCalls)
+			}
+			var httpErr api.HTTPError
+			switch {
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("withRetry() error = %v, want %v", err, tt.wantErr)
+				}
+			case tt.wantStatus != 0:
+				if !errors.As(err, &httpErr) || httpErr.StatusCode != tt.wantStatus {
+					t.Errorf("withRetry() error = %v, want HTTP %d", err, tt.wantStatus)
+				}
+			case err != nil:
+				t.Errorf("withRetry() error = %v, want nil", err)
+			}
+		})
+	}
+}
@@ -1,6 +1,18 @@
 package cli
 
-import "github.com/spf13/cobra"
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/eikster-dk/gh-worktree/internal/config"
+	"github.com/eikster-dk/gh-worktree/internal/worktree"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// prPatterns are the --pr-pattern regular expressions.
+var prPatterns []string
 
 func NewRoot() *cobra.Command {
 	cmd := &cobra.Command{
@@ -11,11 +23,70 @@ func NewRoot() *cobra.Command {
 		SilenceErrors: true,
 		SilenceUsage:  false,
 		Example:       `gh worktree`,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if worktree.Dir != "" {
+				dir, err := filepath.Abs(worktree.Dir)
+				if err != nil {
+					return err
+				}
+				if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+					return fmt.Errorf("cannot change to '%s': not a directory", worktree.Dir)
+				}
+				worktree.Dir = dir
+			}
+
+			if !cmd.Flags().Changed("plain") && (os.Getenv("NO_COLOR") != "" || !term.IsTerminal(int(os.Stdout.Fd()))) {
+				plain = true
+			}
+
+			cfg := loadConfig(cmd.Context())
+			if !cmd.Flags().Changed("concurrency") && cfg.Concurrency != 0 {
+				if cfg.Concurrency < 1 {
+					return fmt.Errorf("%s: concurrency must be at least 1", config.FileName)
+				}
+				concurrency = cfg.Concurrency
+			}
+			if concurrency < 1 {
+				return fmt.Errorf("--concurrency must be at least 1")
+			}
+			worktree.Concurrency = concurrency
+
+			return usePRNumberPatterns(cmd.Context())
+		},
 	}
 
+	cmd.PersistentFlags().StringVarP(&worktree.Dir, "cwd", "C", "", "Run as if gh worktree was started in this directory instead of the current one")
+	cmd.PersistentFlags().StringVarP(&repoOverride, "repo", "R", "", "Select another repository using the [HOST/]OWNER/REPO format")
+	cmd.PersistentFlags().StringVar(&hostnameOverride, "hostname", "", "GitHub host to query, e.g. a GitHub Enterprise server (defaults to the host of the repository's remote)")
+
+	cmd.PersistentFlags().DurationVar(&worktree.GitTimeout, "git-timeout", worktree.GitTimeout, "Kill git commands that run longer than this, e.g. on a stalled network filesystem (0 disables the timeout)")
+	cmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "Only print errors and summaries, without emoji")
+	cmd.PersistentFlags().BoolVar(&plain, "plain", false, "Print ASCII tags such as [ok] and [stale] instead of emoji (on by default when NO_COLOR is set or stdout is not a terminal)")
+	cmd.PersistentFlags().BoolVar(&offline, "offline", false, "Never use the GitHub API; PR statuses come from the local cache only")
+	cmd.PersistentFlags().IntVar(&concurrency, "concurrency", concurrency, "Maximum number of parallel operations, such as PR status requests; lower values ease API rate limits")
+	cmd.PersistentFlags().StringArrayVar(&prPatterns, "pr-pattern", nil, "Regular expression for PR numbers in branch and directory names, whose first capture group is the number (repeatable)")
+
+	cmd.AddCommand(NewAdd())
+	cmd.AddCommand(NewAddPr())
+	cmd.AddCommand(NewAddPrs())
+	cmd.AddCommand(NewAddIssue())
 	cmd.AddCommand(NewClone())
 	cmd.AddCommand(NewPr())
 	cmd.AddCommand(NewClean())
+	cmd.AddCommand(NewList())
+	cmd.AddCommand(NewRemove())
+	cmd.AddCommand(NewPath())
+	cmd.AddCommand(NewSwitch())
+	cmd.AddCommand(NewCurrent())
+	cmd.AddCommand(NewOpenPr())
+	cmd.AddCommand(NewMove())
+	cmd.AddCommand(NewRename())
+	cmd.AddCommand(NewLock())
+	cmd.AddCommand(NewUnlock())
+	cmd.AddCommand(NewStatus())
+	cmd.AddCommand(NewPrune())
+	cmd.AddCommand(NewRepair())
+	cmd.AddCommand(NewDoctor())
 
 	return cmd
 }
@@ -0,0 +1,172 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/eikster-dk/gh-worktree/internal/worktree"
+)
+
+const (
+	staleMetricCommit = "commit"
+	staleMetricBranch = "branch"
+	staleMetricMtime  = "mtime"
+)
+
+func validateStaleMetric(metric string) error {
+	switch metric {
+	case staleMetricCommit, staleMetricBranch, staleMetricMtime:
+		return nil
+	}
+	return fmt.Errorf("invalid stale metric %q: must be one of commit, branch, mtime", metric)
+}
+
+// applyStaleMetric recomputes LastCommit and DaysSinceCommit of each worktree
+// using metric. The commit metric is what getWorktreeInfo already computed.
+func applyStaleMetric(ctx context.Context, worktrees []WorktreeInfo, metric string) {
+	if metric == staleMetricCommit {
+		return
+	}
+
+	base := defaultBranchRef(ctx)
+	for i := range worktrees {
+		if worktrees[i].Bare || worktrees[i].Inaccessible {
+			continue
+		}
+
+		var activity time.Time
+		var err error
+		switch metric {
+		case staleMetricBranch:
+			activity, err = branchActivity(ctx, worktrees[i].Path, base)
+		case staleMetricMtime:
+			activity, err = newestFileModTime(worktrees[i].Path)
+		}
+		if err != nil || activity.IsZero() {
+			continue
+		}
+
+		worktrees[i].LastCommit = activity
+		worktrees[i].DaysSinceCommit = int(time.Since(activity).Hours() / 24)
+	}
+}
+
+// branchActivity returns the date of the newest commit on the worktree's
+// branch that is not on base. A branch that has not diverged from base yet is
+// as old as the worktree itself, so a freshly created worktree is not
+// mistaken for a stale one.
+func branchActivity(ctx context.Context, worktreePath string, base string) (time.Time, error) {
+	if base != "" {
+		if date, err := worktree.LastCommitDate(ctx, worktreePath, base+"..HEAD"); err == nil {
+			return date, nil
+		}
+	}
+	return worktreeCreationTime(ctx, worktreePath)
+}
+
+// worktreeCreationTime returns when the worktree was added, based on the
+// commondir file git writes into the worktree's administrative directory.
+func worktreeCreationTime(ctx context.Context, worktreePath string) (time.Time, error) {
+	gitDir, err := gitOutput(ctx, "-C", worktreePath, "rev-parse", "--absolute-git-dir")
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	info, err := os.Stat(filepath.Join(gitDir, "commondir"))
+	if err != nil {
+		return time.Time{}, err
+	}
+	return info.ModTime(), nil
+}
+
+// newestFileModTime returns the newest modification time of the regular
+// files in the worktree, ignoring the .git entry.
+func newestFileModTime(worktreePath string) (time.Time, error) {
+	var newest time.Time
+	err := filepath.WalkDir(worktreePath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.Name() == ".git" {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if info.ModTime().After(newest) {
+			newest = info.ModTime()
+		}
+		return nil
+	})
+	if err != nil {
+		return time.Time{}, err
+	}
+	if newest.IsZero() {
+		return time.Time{}, fmt.Errorf("no files found")
+	}
+	return newest, nil
+}
+
+// defaultBranchRef returns the remote default branch (e.g. origin/main), or
+// a local main or master branch, or "" when none can be found.
+func defaultBranchRef(ctx context.Context) string {
+	if ref, err := gitOutput(ctx, "symbolic-ref", "--short", "refs/remotes/origin/HEAD"); err == nil {
+		return ref
+	}
+	for _, branch := range defaultProtectedBranches {
+		if _, err := gitOutput(ctx, "rev-parse", "--verify", "--quiet", "refs/heads/"+branch); err == nil {
+			return branch
+		}
+	}
+	return ""
+}
+
+var dayWeekRe = regexp.MustCompile(`(\d+)([dw])`)
+
+// parseSince parses a duration like time.ParseDuration does, additionally
+// accepting d (days) and w (weeks) units, e.g. "2w", "10d" or "1d12h".
+func parseSince(s string) (time.Duration, error) {
+	var total time.Duration
+	rest := dayWeekRe.ReplaceAllStringFunc(s, func(m string) string {
+		parts := dayWeekRe.FindStringSubmatch(m)
+		n, _ := strconv.Atoi(parts[1])
+		unit := 24 * time.Hour
+		if parts[2] == "w" {
+			unit *= 7
+		}
+		total += time.Duration(n) * unit
+		return ""
+	})
+	if rest != "" {
+		d, err := time.ParseDuration(rest)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q: use a duration like 72h, 10d or 2w", s)
+		}
+		total += d
+	}
+	if total <= 0 {
+		return 0, fmt.Errorf("invalid duration %q: must be positive", s)
+	}
+	return total, nil
+}
+
+// formatStaleAfter describes a staleness threshold for humans.
+func formatStaleAfter(d time.Duration) string {
+	if d%(24*time.Hour) == 0 {
+		return fmt.Sprintf("%d+ days", d/(24*time.Hour))
+	}
+	return d.String() + "+"
+}
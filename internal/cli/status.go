@@ -0,0 +1,78 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+func NewStatus() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "status",
+		Short:   "Show uncommitted changes and ahead/behind counts for each worktree",
+		Example: "gh worktree status",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			worktrees, err := getWorktreeInfo(cmd.Context())
+			if err != nil {
+				return fmt.Errorf("failed to get worktree info: %w", err)
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "PATH\tBRANCH\tUPSTREAM\tDIRTY\tAHEAD\tBEHIND")
+			for _, wt := range worktrees {
+				if wt.Bare {
+					continue
+				}
+
+				dirty := "?"
+				if d, err := hasUncommittedChanges(cmd.Context(), wt.Path); err == nil {
+					dirty = "no"
+					if d {
+						dirty = "yes"
+					}
+				}
+
+				ahead, behind := "-", "-"
+				if a, b, ok := getAheadBehind(cmd.Context(), wt.Path); ok {
+					ahead, behind = strconv.Itoa(a), strconv.Itoa(b)
+				}
+
+				upstream := wt.Upstream
+				if upstream == "" {
+					upstream = "-"
+				}
+
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", wt.Path, wt.displayBranch(), upstream, dirty, ahead, behind)
+			}
+			return w.Flush()
+		},
+	}
+
+	return cmd
+}
+
+// getAheadBehind returns how many commits HEAD is ahead of and behind its
+// upstream. ok is false when the worktree has no upstream.
+func getAheadBehind(ctx context.Context, worktreePath string) (ahead int, behind int, ok bool) {
+	cmd, err := gitCommand(ctx, "-C", worktreePath, "rev-list", "--left-right", "--count", "@{u}...HEAD")
+	if err != nil {
+		return 0, 0, false
+	}
+	output, err := cmd.Output()
+	if err != nil {
+		return 0, 0, false
+	}
+
+	counts := strings.Fields(string(output))
+	if len(counts) != 2 {
+		return 0, 0, false
+	}
+	behind, _ = strconv.Atoi(counts[0])
+	ahead, _ = strconv.Atoi(counts[1])
+	return ahead, behind, true
+}
@@ -0,0 +1,83 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/eikster-dk/gh-worktree/internal/worktree"
+	"github.com/spf13/cobra"
+)
+
+func NewSwitch() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "switch <branch | pr-number>",
+		Short: "Make a worktree the current one of the repository",
+		Long: `Records the worktree for a branch or PR number as the current one, for tools and
+prompts that want to know which worktree you are focused on. Read it back with
+gh worktree current. The record is shared by all worktrees of the repository.
+
+A subprocess cannot change the directory of your shell, so this does not cd
+into the worktree; combine it with gh worktree path for that:
+
+  gh worktree switch feature-x && cd "$(gh worktree current)"`,
+		Example: `gh worktree switch feature-x
+gh worktree switch 1234`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(args) < 1 {
+				return errors.New("a branch name or pr number is required")
+			}
+
+			return nil
+		},
+		ValidArgsFunction: completeWorktreeBranches,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path, err := resolveWorktreePath(cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+
+			path, err = worktree.AbsPath(path)
+			if err != nil {
+				return err
+			}
+
+			if err := worktree.SetCurrent(cmd.Context(), path); err != nil {
+				return err
+			}
+
+			newOutput(false).Essentialf("👉 Switched to %s\n", path)
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+func NewCurrent() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "current",
+		Short: "Print the path of the current worktree chosen with switch",
+		Long: `Prints only the absolute path of the worktree last chosen with gh worktree switch,
+so it can be used in shell substitution and prompts. Fails if none was chosen
+or the worktree no longer exists.`,
+		Example:      `cd "$(gh worktree current)"`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path, err := worktree.Current(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			if info, err := os.Stat(path); err != nil || !info.IsDir() {
+				return fmt.Errorf("current worktree %s no longer exists, choose another with gh worktree switch", path)
+			}
+
+			fmt.Println(path)
+			return nil
+		},
+	}
+
+	return cmd
+}
@@ -0,0 +1,89 @@
+// Package config loads per-repository defaults from a .gh-worktree.yml file
+// in the repository root.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/eikster-dk/gh-worktree/internal/worktree"
+	"gopkg.in/yaml.v3"
+)
+
+// FileName is the name of the config file looked up in the repository root.
+const FileName = ".gh-worktree.yml"
+
+// Config holds defaults for command flags. Flags given on the command line
+// take precedence over these values.
+type Config struct {
+	// StaleDays is the default for clean --stale-days.
+	StaleDays int `yaml:"stale_days"`
+
+	// Protect lists branch names or glob patterns clean never removes.
+	Protect []string `yaml:"protect"`
+
+	// BasePath is the directory new worktrees are created in by add.
+	// Environment variables and a leading ~ are expanded, and a relative path
+	// is resolved against the repository root.
+	BasePath string `yaml:"base_path"`
+
+	// Layout is the default for add --layout, a template for the path of
+	// new worktrees.
+	Layout string `yaml:"layout"`
+
+	// PRPatterns are regular expressions for PR numbers in branch and
+	// directory names, tried before the built-in conventions. The first
+	// capture group is the PR number.
+	PRPatterns []string `yaml:"pr_patterns"`
+
+	// Concurrency is the default for --concurrency.
+	Concurrency int `yaml:"concurrency"`
+
+	// CopyConfig are git config keys add copies from the current worktree
+	// into new worktrees, the default for add --copy-config.
+	CopyConfig []string `yaml:"copy_config"`
+
+	// RepoDirs are the repositories clean cleans when no --repo-dir is
+	// given. Environment variables and a leading ~ are expanded, and
+	// relative paths are resolved against the repository root.
+	RepoDirs []string `yaml:"repo_dirs"`
+}
+
+// Load reads the config file from root. A missing file results in an empty
+// config and no error.
+func Load(root string) (Config, error) {
+	var cfg Config
+
+	b, err := os.ReadFile(filepath.Join(root, FileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, err
+	}
+
+	if err := yaml.Unmarshal(b, &cfg); err != nil {
+		return Config{}, fmt.Errorf("invalid %s: %w", FileName, err)
+	}
+
+	if cfg.BasePath != "" {
+		if cfg.BasePath, err = worktree.ExpandPath(cfg.BasePath); err != nil {
+			return Config{}, err
+		}
+	}
+	if cfg.BasePath != "" && !filepath.IsAbs(cfg.BasePath) {
+		cfg.BasePath = filepath.Join(root, cfg.BasePath)
+	}
+	for i, dir := range cfg.RepoDirs {
+		if dir, err = worktree.ExpandPath(dir); err != nil {
+			return Config{}, err
+		}
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(root, dir)
+		}
+		cfg.RepoDirs[i] = dir
+	}
+	return cfg, nil
+}
@@ -0,0 +1,108 @@
+package worktree
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// planCopy returns the files matching patterns in the root of the current
+// worktree and where they go in dest, at the same relative location.
+func planCopy(ctx context.Context, dest string, patterns []string) ([]FileCopy, error) {
+	out, err := gitContext(ctx, []string{"rev-parse", "--show-toplevel"})
+	if err != nil {
+		return nil, fmt.Errorf("copying files requires running from inside a worktree: %w", err)
+	}
+	root := NormalizePath(strings.TrimSpace(string(out)))
+
+	var files []FileCopy
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(filepath.Join(root, pattern))
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+
+		for _, src := range matches {
+			rel, err := filepath.Rel(root, src)
+			if err != nil {
+				return nil, err
+			}
+			files = append(files, FileCopy{Source: src, Target: filepath.Join(dest, rel)})
+		}
+	}
+	return files, nil
+}
+
+// copyFiles copies (or symlinks) files into place.
+func copyFiles(files []FileCopy, symlink bool) error {
+	for _, f := range files {
+		if err := os.MkdirAll(filepath.Dir(f.Target), 0o755); err != nil {
+			return err
+		}
+		var err error
+		if symlink {
+			err = os.Symlink(f.Source, f.Target)
+		} else {
+			err = copyPath(f.Source, f.Target)
+		}
+		if err != nil {
+			return fmt.Errorf("could not copy %s: %w", f.Source, err)
+		}
+	}
+	return nil
+}
+
+// copyPath recursively copies src to dst, recreating symlinks as they are.
+func copyPath(src string, dst string) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case d.IsDir():
+			return os.MkdirAll(target, info.Mode().Perm())
+		case d.Type()&fs.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		default:
+			return copyFile(path, target, info.Mode().Perm())
+		}
+	})
+}
+
+func copyFile(src string, dst string, perm fs.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,49 @@
+package worktree
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// currentFile is the name of the file in the git common directory that holds
+// the path of the current worktree. Being in the common directory it is
+// shared by all worktrees of the repository.
+const currentFile = "gh-worktree-current"
+
+// SetCurrent records path as the worktree the user is focused on.
+func SetCurrent(ctx context.Context, path string) error {
+	commonDir, err := gitCommonDir(ctx)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(commonDir, currentFile), []byte(path+"\n"), 0o644); err != nil {
+		return fmt.Errorf("could not save the current worktree: %w", err)
+	}
+	return nil
+}
+
+// Current returns the path recorded by SetCurrent, or an error matching
+// ErrNoCurrent if there is none.
+func Current(ctx context.Context) (string, error) {
+	commonDir, err := gitCommonDir(ctx)
+	if err != nil {
+		return "", err
+	}
+	b, err := os.ReadFile(filepath.Join(commonDir, currentFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return "", &codedError{ErrNoCurrent, "no current worktree, choose one with gh worktree switch"}
+	}
+	if err != nil {
+		return "", fmt.Errorf("could not read the current worktree: %w", err)
+	}
+
+	path := strings.TrimSpace(string(b))
+	if path == "" {
+		return "", &codedError{ErrNoCurrent, "no current worktree, choose one with gh worktree switch"}
+	}
+	return path, nil
+}
@@ -0,0 +1,40 @@
+package worktree
+
+import "errors"
+
+// Errors returned by this package, wrapped in errors carrying the details.
+// Match them with errors.Is.
+var (
+	// ErrBranchNotFound means the branch exists neither locally nor on the
+	// remote it was fetched from.
+	ErrBranchNotFound = errors.New("branch not found")
+
+	// ErrWorktreeExists means the branch is already checked out in a
+	// worktree.
+	ErrWorktreeExists = errors.New("worktree already exists")
+
+	// ErrWorktreeNotFound means no worktree has the branch checked out.
+	ErrWorktreeNotFound = errors.New("worktree not found")
+
+	// ErrDirExists means the directory the worktree would be created in
+	// already exists.
+	ErrDirExists = errors.New("directory already exists")
+
+	// ErrNoCurrent means no worktree was chosen with SetCurrent yet.
+	ErrNoCurrent = errors.New("no current worktree")
+)
+
+// codedError is an error matching one of the sentinel errors above while
+// keeping its own message.
+type codedError struct {
+	code error
+	msg  string
+}
+
+func (e *codedError) Error() string {
+	return e.msg
+}
+
+func (e *codedError) Unwrap() error {
+	return e.code
+}
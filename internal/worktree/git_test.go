@@ -0,0 +1,63 @@
+package worktree
+
+import (
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newTestRepo creates a repository with one commit on branch and makes it
+// the directory git commands run in for the rest of the test. Its path has
+// symlinks resolved, so it compares equal to the paths git prints.
+func newTestRepo(t *testing.T, branch string) string {
+	t.Helper()
+	setupGit(t)
+
+	dir := evalSymlinks(t, t.TempDir())
+	runGit(t, dir, "init", "-q", "-b", branch)
+	runGit(t, dir, "commit", "-q", "--allow-empty", "-m", "initial")
+
+	saved := Dir
+	Dir = dir
+	t.Cleanup(func() { Dir = saved })
+	return dir
+}
+
+// setupGit skips the test without git and isolates it from the user's git
+// configuration.
+func setupGit(t *testing.T) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	for _, name := range []string{"GIT_AUTHOR_NAME", "GIT_COMMITTER_NAME"} {
+		t.Setenv(name, "test")
+	}
+	for _, name := range []string{"GIT_AUTHOR_EMAIL", "GIT_COMMITTER_EMAIL"} {
+		t.Setenv(name, "test@example.com")
+	}
+	t.Setenv("GIT_CONFIG_GLOBAL", "/dev/null")
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+}
+
+// runGit runs git with args in dir and returns its trimmed output.
+func runGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	c := exec.Command("git", args...)
+	c.Dir = dir
+	output, err := c.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, output)
+	}
+	return strings.TrimSpace(string(output))
+}
+
+func evalSymlinks(t *testing.T, path string) string {
+	t.Helper()
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resolved
+}
@@ -0,0 +1,51 @@
+package worktree
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// ConfigValue is a git config key with its value in the worktree a new
+// worktree is created from.
+type ConfigValue struct {
+	Key   string
+	Value string
+}
+
+// planConfig reads the effective value of keys in the current worktree,
+// i.e. the last one git finds across the system, global, repository and
+// worktree config. Keys that are not set are left out.
+func planConfig(ctx context.Context, keys []string) ([]ConfigValue, error) {
+	var values []ConfigValue
+	for _, key := range keys {
+		if !strings.Contains(key, ".") {
+			return nil, fmt.Errorf("invalid git config key %q: must be of the form section.name", key)
+		}
+		// git config exits with 1 when the key is not set
+		out, err := gitContext(ctx, []string{"config", "--get", key})
+		if err != nil {
+			continue
+		}
+		values = append(values, ConfigValue{Key: key, Value: strings.TrimRight(string(out), "\n")})
+	}
+	return values, nil
+}
+
+// copyConfig sets values in the worktree at path, skipping those it already
+// sees through the config shared by all worktrees. The others are written to
+// its worktree-specific config, which needs git's worktreeConfig extension.
+func copyConfig(ctx context.Context, path string, values []ConfigValue) error {
+	for _, v := range values {
+		if out, err := gitContext(ctx, []string{"-C", path, "config", "--get", v.Key}); err == nil && strings.TrimRight(string(out), "\n") == v.Value {
+			continue
+		}
+		if _, err := gitContext(ctx, []string{"-C", path, "config", "--worktree", v.Key, v.Value}); err != nil {
+			if strings.Contains(err.Error(), "worktreeConfig") {
+				return fmt.Errorf("could not set %s: enable worktree-specific config with 'git config extensions.worktreeConfig true' first", v.Key)
+			}
+			return fmt.Errorf("could not set %s: %w", v.Key, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package worktree
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+)
+
+// DefaultLayout places a worktree in a directory named after its branch.
+const DefaultLayout = "{{.branch}}"
+
+// slugLayout places a worktree in a single directory named after the slug of
+// its branch.
+const slugLayout = "{{slug .branch}}"
+
+// RenderLayout executes the text/template layout to get the path of the
+// worktree for branch. The template can use {{.branch}} plus the given vars,
+// typically {{.repo}}, {{.owner}} and {{.pr}}, and the slug function, e.g.
+// "~/worktrees/{{.repo}}/{{.pr}}-{{slug .branch}}". A leading ~ is expanded
+// to the home directory.
+//
+// Variables are sanitized before they are filled in: characters that are
+// unsafe in directory names are replaced and "." and ".." segments are
+// dropped, so a branch name can't place a worktree outside the directory the
+// layout intends. Slashes in branch names still nest directories, as they do
+// with DefaultLayout.
+func RenderLayout(layout string, branch string, vars map[string]interface{}) (string, error) {
+	tmpl, err := template.New("layout").Funcs(template.FuncMap{"slug": Slug}).Option("missingkey=error").Parse(layout)
+	if err != nil {
+		return "", fmt.Errorf("invalid layout %q: %w", layout, err)
+	}
+
+	data := map[string]interface{}{"branch": branch, "repo": "", "owner": "", "pr": ""}
+	for k, v := range vars {
+		data[k] = v
+	}
+	for k, v := range data {
+		if s, ok := v.(string); ok {
+			data[k] = sanitizePath(s)
+		}
+	}
+
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("invalid layout %q: %w", layout, err)
+	}
+	rendered := strings.TrimSpace(b.String())
+	if rendered == "" {
+		return "", fmt.Errorf("layout %q rendered an empty path", layout)
+	}
+
+	rendered, err = expandHome(rendered)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Clean(rendered), nil
+}
+
+// ExpandPath expands environment variables and a leading ~ in path.
+func ExpandPath(path string) (string, error) {
+	return expandHome(os.ExpandEnv(path))
+}
+
+func expandHome(path string) (string, error) {
+	if path != "~" && !strings.HasPrefix(path, "~/") && !strings.HasPrefix(path, "~"+string(filepath.Separator)) {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return home + path[1:], nil
+}
+
+var unsafePathCharsRe = regexp.MustCompile(`[\x00-\x1f<>:"|?*\\]+`)
+
+// sanitizePath replaces characters that are unsafe in directory names and
+// drops empty, "." and ".." segments of a slash separated relative path.
+func sanitizePath(path string) string {
+	var segments []string
+	for _, segment := range strings.Split(path, "/") {
+		segment = strings.TrimSpace(unsafePathCharsRe.ReplaceAllString(segment, "-"))
+		if segment == "" || segment == "." || segment == ".." {
+			continue
+		}
+		segments = append(segments, segment)
+	}
+	return strings.Join(segments, "/")
+}
@@ -0,0 +1,268 @@
+package worktree
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Info describes a worktree as reported by git worktree list.
+type Info struct {
+	// Path is the absolute path of the worktree.
+	Path string `json:"path"`
+
+	// Branch is the short name of the checked out branch. It is empty for
+	// detached and bare worktrees.
+	Branch string `json:"branch"`
+
+	// Head is the full SHA of the commit checked out in the worktree.
+	Head string `json:"head"`
+
+	// Detached reports whether HEAD is detached.
+	Detached bool `json:"detached"`
+
+	// Bare reports whether this entry is a bare repository.
+	Bare bool `json:"bare"`
+
+	// IsMain reports whether this is the main worktree (or the bare
+	// repository), which git always lists first.
+	IsMain bool `json:"isMain"`
+
+	// Locked reports whether the worktree is locked, with the optional
+	// reason given when it was locked.
+	Locked     bool   `json:"locked"`
+	LockReason string `json:"lockReason"`
+
+	// LastCommit is the committer date of the worktree's HEAD commit. It is
+	// zero for bare repositories and when the date could not be read.
+	LastCommit time.Time `json:"lastCommit"`
+
+	// Inaccessible reports whether the worktree directory could not be
+	// read, e.g. because it lives on an unmounted volume or was deleted.
+	Inaccessible bool `json:"inaccessible"`
+
+	// Missing reports whether the inaccessible worktree directory does not
+	// exist while its parent does, which usually means it was deleted. See
+	// IsMissing.
+	Missing bool `json:"missing"`
+
+	// Upstream is the short name of the branch's upstream, e.g.
+	// origin/feature-x. It is empty when the branch tracks nothing.
+	Upstream string `json:"upstream"`
+}
+
+// List wraps ListContext with context.Background.
+func List() ([]Info, error) {
+	return ListContext(context.Background())
+}
+
+// ListContext returns all worktrees of the current repository in the order
+// reported by git, starting with the main worktree.
+func ListContext(ctx context.Context) ([]Info, error) {
+	worktrees, err := listPorcelain(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	readWorktreeDates(ctx, worktrees)
+
+	// Upstreams are looked up for all branches at once. Without them the
+	// worktrees are still listed, just without an upstream.
+	if upstreams, err := branchUpstreams(ctx); err == nil {
+		for i := range worktrees {
+			worktrees[i].Upstream = upstreams[worktrees[i].Branch]
+		}
+	}
+	return worktrees, nil
+}
+
+// Concurrency bounds the number of git commands List runs in parallel. It is
+// set by the global --concurrency flag.
+var Concurrency = 8
+
+// readWorktreeDates marks worktrees whose directory can't be read as
+// inaccessible, and also as missing when it does not exist, and sets the last
+// commit date of the others, running at most Concurrency git commands at a
+// time. A worktree whose date can't be read keeps a zero LastCommit without
+// affecting the others.
+func readWorktreeDates(ctx context.Context, worktrees []Info) {
+	workers := Concurrency
+	if workers < 1 {
+		workers = 1
+	}
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// Each worker only writes the worktrees whose index it received,
+			// so the slice order and contents don't depend on scheduling.
+			for i := range jobs {
+				if _, err := os.Stat(worktrees[i].Path); err != nil {
+					worktrees[i].Inaccessible = true
+					worktrees[i].Missing = IsMissing(worktrees[i].Path)
+					continue
+				}
+				if lastCommit, err := lastCommitDate(ctx, worktrees[i].Path); err == nil {
+					worktrees[i].LastCommit = lastCommit
+				}
+			}
+		}()
+	}
+
+	for i := range worktrees {
+		if !worktrees[i].Bare {
+			jobs <- i
+		}
+	}
+	close(jobs)
+	wg.Wait()
+}
+
+// listPorcelain runs git worktree list --porcelain and parses its output.
+// The -z format, which git supports since 2.36, is preferred because it also
+// keeps paths containing newlines intact.
+func listPorcelain(ctx context.Context) ([]Info, error) {
+	output, err := gitContext(ctx, []string{"worktree", "list", "--porcelain", "-z"})
+	if err == nil {
+		return parsePorcelain(string(output), "\x00"), nil
+	}
+	// Older git rejects -z as an unknown option
+	output, err = gitContext(ctx, []string{"worktree", "list", "--porcelain"})
+	if err != nil {
+		return nil, err
+	}
+	return parsePorcelain(string(output), "\n"), nil
+}
+
+// parsePorcelain parses the output of git worktree list --porcelain, whose
+// attributes are terminated by sep: a newline, or NUL with -z. Records end
+// with an empty attribute.
+func parsePorcelain(output string, sep string) []Info {
+	var worktrees []Info
+	var current Info
+
+	for _, line := range strings.Split(output, sep) {
+		switch {
+		case strings.HasPrefix(line, "worktree "):
+			if current.Path != "" {
+				worktrees = append(worktrees, current)
+			}
+			current = Info{Path: NormalizePath(strings.TrimPrefix(line, "worktree "))}
+		case strings.HasPrefix(line, "branch refs/heads/"):
+			current.Branch = strings.TrimPrefix(line, "branch refs/heads/")
+		case strings.HasPrefix(line, "HEAD "):
+			current.Head = strings.TrimPrefix(line, "HEAD ")
+		case line == "detached":
+			current.Detached = true
+		case line == "bare":
+			current.Bare = true
+		case line == "locked" || strings.HasPrefix(line, "locked "):
+			current.Locked = true
+			current.LockReason = strings.TrimPrefix(strings.TrimPrefix(line, "locked"), " ")
+		case line == "" && current.Path != "":
+			worktrees = append(worktrees, current)
+			current = Info{}
+		}
+	}
+	if current.Path != "" {
+		worktrees = append(worktrees, current)
+	}
+
+	if len(worktrees) > 0 {
+		worktrees[0].IsMain = true
+	}
+	return worktrees
+}
+
+// branchUpstreams maps local branch names to the short name of their
+// upstream. Branches without an upstream are left out.
+func branchUpstreams(ctx context.Context) (map[string]string, error) {
+	output, err := gitContext(ctx, []string{"for-each-ref", "--format=%(refname)%00%(upstream:short)", "refs/heads"})
+	if err != nil {
+		return nil, err
+	}
+
+	upstreams := map[string]string{}
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		ref, upstream, found := strings.Cut(line, "\x00")
+		if !found || upstream == "" {
+			continue
+		}
+		upstreams[strings.TrimPrefix(ref, "refs/heads/")] = upstream
+	}
+	return upstreams, nil
+}
+
+// LastCommitDate returns the committer date of the newest commit in the
+// worktree at path, optionally limited to the given revisions (e.g.
+// "main..HEAD").
+func LastCommitDate(ctx context.Context, path string, revs ...string) (time.Time, error) {
+	return lastCommitDate(ctx, path, revs...)
+}
+
+func lastCommitDate(ctx context.Context, path string, revs ...string) (time.Time, error) {
+	args := append([]string{"-C", path, "log", "-1", "--format=%ct"}, revs...)
+	output, err := gitContext(ctx, args)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	timestamp := strings.TrimSpace(string(output))
+	if timestamp == "" {
+		return time.Time{}, fmt.Errorf("no commits found")
+	}
+
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(unix, 0), nil
+}
+
+// IsMissing reports whether the worktree directory at path does not exist
+// while the directory containing it does, which usually means it was
+// deleted. It can't tell a deleted directory from one on a volume that is not
+// mounted over an empty mount point, so it is no reason to remove a worktree
+// without being asked to.
+func IsMissing(path string) bool {
+	if _, err := os.Stat(path); !errors.Is(err, fs.ErrNotExist) {
+		return false
+	}
+	info, err := os.Stat(filepath.Dir(path))
+	return err == nil && info.IsDir()
+}
+
+// RealPath returns path with symlinks resolved, or path itself when it can't
+// be resolved, e.g. because it does not exist.
+func RealPath(path string) string {
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		return resolved
+	}
+	return path
+}
+
+// SamePath reports whether a and b are the same directory once separators
+// are normalized and symlinks are resolved.
+func SamePath(a string, b string) bool {
+	a, b = NormalizePath(a), NormalizePath(b)
+	return a == b || RealPath(a) == RealPath(b)
+}
+
+// NormalizePath converts a path printed by git, which uses forward slashes
+// even on Windows, to a clean path with the separators of the OS.
+func NormalizePath(path string) string {
+	if path == "" {
+		return ""
+	}
+	return filepath.Clean(filepath.FromSlash(path))
+}
@@ -0,0 +1,296 @@
+package worktree
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestParsePorcelainMainWorktree(t *testing.T) {
+	tests := []struct {
+		name       string
+		output     string
+		wantBranch string
+		wantDetach bool
+	}{
+		{
+			name: "detached HEAD",
+			output: "worktree /src/app\nHEAD 1111111111111111111111111111111111111111\ndetached\n\n" +
+				"worktree /src/feature\nHEAD 2222222222222222222222222222222222222222\nbranch refs/heads/feature\n\n",
+			wantDetach: true,
+		},
+		{
+			name: "default branch is neither main nor master",
+			output: "worktree /src/app\nHEAD 1111111111111111111111111111111111111111\nbranch refs/heads/trunk\n\n" +
+				"worktree /src/main\nHEAD 2222222222222222222222222222222222222222\nbranch refs/heads/main\n\n",
+			wantBranch: "trunk",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			worktrees := parsePorcelain(tt.output, "\n")
+			if len(worktrees) != 2 {
+				t.Fatalf("parsePorcelain() returned %d worktrees, want 2", len(worktrees))
+			}
+			main := worktrees[0]
+			if !main.IsMain || main.Path != filepath.FromSlash("/src/app") {
+				t.Errorf("first worktree = %+v, want the main worktree at /src/app", main)
+			}
+			if main.Branch != tt.wantBranch || main.Detached != tt.wantDetach {
+				t.Errorf("main worktree branch = %q, detached = %v, want %q, %v", main.Branch, main.Detached, tt.wantBranch, tt.wantDetach)
+			}
+			if worktrees[1].IsMain {
+				t.Errorf("second worktree %s is marked as the main worktree", worktrees[1].Path)
+			}
+		})
+	}
+}
+
+func TestListMainWorktree(t *testing.T) {
+	tests := []struct {
+		name   string
+		branch string
+		detach bool
+	}{
+		{"default branch trunk", "trunk", false},
+		{"detached HEAD", "trunk", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := newTestRepo(t, tt.branch)
+			linked := filepath.Join(filepath.Dir(dir), filepath.Base(dir)+"-main")
+			// A linked worktree on a branch named main must not be taken for
+			// the main worktree
+			runGit(t, dir, "worktree", "add", "-q", "-b", "main", linked)
+			if tt.detach {
+				runGit(t, dir, "checkout", "-q", "--detach")
+			}
+
+			worktrees, err := ListContext(context.Background())
+			if err != nil {
+				t.Fatalf("ListContext() error = %v", err)
+			}
+			if len(worktrees) != 2 {
+				t.Fatalf("ListContext() returned %d worktrees, want 2", len(worktrees))
+			}
+			for _, wt := range worktrees {
+				if wantMain := wt.Path == dir; wt.IsMain != wantMain {
+					t.Errorf("%s: IsMain = %v, want %v", wt.Path, wt.IsMain, wantMain)
+				}
+			}
+			if worktrees[0].Detached != tt.detach {
+				t.Errorf("main worktree Detached = %v, want %v", worktrees[0].Detached, tt.detach)
+			}
+		})
+	}
+}
+
+func TestParsePorcelainDetached(t *testing.T) {
+	output := "worktree /src/app\nHEAD 1111111111111111111111111111111111111111\nbranch refs/heads/main\n\n" +
+		"worktree /src/pr-77-review\nHEAD 2222222222222222222222222222222222222222\ndetached\n\n"
+
+	worktrees := parsePorcelain(output, "\n")
+	if len(worktrees) != 2 {
+		t.Fatalf("parsePorcelain() returned %d worktrees, want 2", len(worktrees))
+	}
+	wt := worktrees[1]
+	if !wt.Detached || wt.Branch != "" || wt.Head != "2222222222222222222222222222222222222222" {
+		t.Errorf("detached worktree = %+v, want Detached with HEAD 2222… and no branch", wt)
+	}
+	if worktrees[0].Detached {
+		t.Errorf("worktree on main is marked as detached")
+	}
+}
+
+func TestParsePorcelainLocked(t *testing.T) {
+	output := "worktree /src/app\nHEAD 1111111111111111111111111111111111111111\nbranch refs/heads/main\n\n" +
+		"worktree /src/usb\nHEAD 2222222222222222222222222222222222222222\nbranch refs/heads/usb\nlocked on the external drive\n\n" +
+		"worktree /src/keep\nHEAD 3333333333333333333333333333333333333333\nbranch refs/heads/keep\nlocked\n\n" +
+		"worktree /src/free\nHEAD 4444444444444444444444444444444444444444\nbranch refs/heads/free\n\n"
+
+	tests := []struct {
+		branch     string
+		locked     bool
+		lockReason string
+	}{
+		{"main", false, ""},
+		{"usb", true, "on the external drive"},
+		{"keep", true, ""},
+		{"free", false, ""},
+	}
+	worktrees := parsePorcelain(output, "\n")
+	if len(worktrees) != len(tests) {
+		t.Fatalf("parsePorcelain() returned %d worktrees, want %d", len(worktrees), len(tests))
+	}
+	for i, tt := range tests {
+		wt := worktrees[i]
+		if wt.Branch != tt.branch || wt.Locked != tt.locked || wt.LockReason != tt.lockReason {
+			t.Errorf("worktree %d = branch %q, locked %v, reason %q, want %q, %v, %q", i, wt.Branch, wt.Locked, wt.LockReason, tt.branch, tt.locked, tt.lockReason)
+		}
+	}
+}
+
+func TestListLocked(t *testing.T) {
+	dir := newTestRepo(t, "main")
+	usb := filepath.Join(filepath.Dir(dir), "usb")
+	keep := filepath.Join(filepath.Dir(dir), "keep")
+	runGit(t, dir, "worktree", "add", "-q", "-b", "usb", usb)
+	runGit(t, dir, "worktree", "add", "-q", "-b", "keep", keep)
+	runGit(t, dir, "worktree", "lock", "--reason", "on the external drive", usb)
+	runGit(t, dir, "worktree", "lock", keep)
+
+	worktrees, err := ListContext(context.Background())
+	if err != nil {
+		t.Fatalf("ListContext() error = %v", err)
+	}
+	want := map[string]string{usb: "on the external drive", keep: ""}
+	for _, wt := range worktrees {
+		reason, locked := want[wt.Path]
+		if wt.Locked != locked || wt.LockReason != reason {
+			t.Errorf("%s: Locked = %v, LockReason = %q, want %v, %q", wt.Path, wt.Locked, wt.LockReason, locked, reason)
+		}
+	}
+}
+
+func TestListMissing(t *testing.T) {
+	dir := newTestRepo(t, "main")
+	deleted := filepath.Join(filepath.Dir(dir), "deleted")
+	// The volume holding the parent directory is not mounted
+	unmounted := filepath.Join(filepath.Dir(dir), "volume", "unmounted")
+	intact := filepath.Join(filepath.Dir(dir), "intact")
+	for _, path := range []string{deleted, unmounted, intact} {
+		runGit(t, dir, "worktree", "add", "-q", "-b", filepath.Base(path), path)
+	}
+	if err := os.RemoveAll(deleted); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.RemoveAll(filepath.Dir(unmounted)); err != nil {
+		t.Fatal(err)
+	}
+
+	worktrees, err := ListContext(context.Background())
+	if err != nil {
+		t.Fatalf("ListContext() error = %v", err)
+	}
+	want := map[string][2]bool{
+		dir:       {false, false},
+		deleted:   {true, true},
+		unmounted: {true, false},
+		intact:    {false, false},
+	}
+	for _, wt := range worktrees {
+		w := want[wt.Path]
+		if wt.Inaccessible != w[0] || wt.Missing != w[1] {
+			t.Errorf("%s: Inaccessible = %v, Missing = %v, want %v, %v", wt.Path, wt.Inaccessible, wt.Missing, w[0], w[1])
+		}
+	}
+}
+
+// porcelainRecords are the records of porcelainFixture, in order.
+var porcelainRecords = []Info{
+	{Path: "/src/repo.git", Bare: true, IsMain: true},
+	{Path: "/src/new\nline", Head: "1111111111111111111111111111111111111111", Branch: "feature"},
+	{Path: "/src/review", Head: "2222222222222222222222222222222222222222", Detached: true},
+	{Path: "/src/usb", Head: "3333333333333333333333333333333333333333", Branch: "usb", Locked: true, LockReason: "on the external drive"},
+	{Path: "/src/keep", Head: "4444444444444444444444444444444444444444", Branch: "keep", Locked: true},
+}
+
+// porcelainFixture renders the records of git worktree list --porcelain,
+// terminating each attribute with sep.
+func porcelainFixture(sep string) string {
+	lines := []string{
+		"worktree /src/repo.git", "bare", "",
+		"worktree /src/new\nline", "HEAD 1111111111111111111111111111111111111111", "branch refs/heads/feature", "",
+		"worktree /src/review", "HEAD 2222222222222222222222222222222222222222", "detached", "",
+		"worktree /src/usb", "HEAD 3333333333333333333333333333333333333333", "branch refs/heads/usb", "locked on the external drive", "",
+		"worktree /src/keep", "HEAD 4444444444444444444444444444444444444444", "branch refs/heads/keep", "locked", "",
+	}
+	return strings.Join(lines, sep) + sep
+}
+
+func TestParsePorcelainNUL(t *testing.T) {
+	worktrees := parsePorcelain(porcelainFixture("\x00"), "\x00")
+	if len(worktrees) != len(porcelainRecords) {
+		t.Fatalf("parsePorcelain() returned %d worktrees, want %d", len(worktrees), len(porcelainRecords))
+	}
+	for i, want := range porcelainRecords {
+		want.Path = filepath.FromSlash(want.Path)
+		if !reflect.DeepEqual(worktrees[i], want) {
+			t.Errorf("worktree %d = %+v, want %+v", i, worktrees[i], want)
+		}
+	}
+}
+
+func TestParsePorcelainNewline(t *testing.T) {
+	// Without -z the newline in a path splits its record, but the other
+	// records are parsed as they are with -z
+	worktrees := parsePorcelain(porcelainFixture("\n"), "\n")
+	byPath := map[string]Info{}
+	for _, wt := range worktrees {
+		byPath[filepath.ToSlash(wt.Path)] = wt
+	}
+	for _, want := range porcelainRecords {
+		if strings.Contains(want.Path, "\n") {
+			continue
+		}
+		got, ok := byPath[want.Path]
+		want.Path = filepath.FromSlash(want.Path)
+		if !ok || !reflect.DeepEqual(got, want) {
+			t.Errorf("worktree %s = %+v, want %+v", want.Path, got, want)
+		}
+	}
+	if !worktrees[0].IsMain || !worktrees[0].Bare {
+		t.Errorf("first worktree = %+v, want the bare main worktree", worktrees[0])
+	}
+}
+
+func TestListPorcelain(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("directory names can't contain newlines")
+	}
+	dir := newTestRepo(t, "main")
+	odd := filepath.Join(filepath.Dir(dir), "new\nline")
+	runGit(t, dir, "worktree", "add", "-q", "-b", "feature", odd)
+
+	worktrees, err := listPorcelain(context.Background())
+	if err != nil {
+		t.Fatalf("listPorcelain() error = %v", err)
+	}
+	if len(worktrees) != 2 || worktrees[1].Path != odd || worktrees[1].Branch != "feature" {
+		t.Errorf("listPorcelain() = %+v, want the worktree at %q on feature", worktrees, odd)
+	}
+}
+
+func TestListPorcelainWithoutNUL(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake git is a shell script")
+	}
+	dir := newTestRepo(t, "main")
+	other := filepath.Join(filepath.Dir(dir), "other")
+	runGit(t, dir, "worktree", "add", "-q", "-b", "other", other)
+
+	// A git older than 2.36 rejects -z
+	realGit, err := exec.LookPath("git")
+	if err != nil {
+		t.Fatal(err)
+	}
+	bin := t.TempDir()
+	script := "#!/bin/sh\nfor arg in \"$@\"; do\n\tif [ \"$arg\" = -z ]; then echo \"error: unknown switch \\`z'\" >&2; exit 129; fi\ndone\nexec '" + realGit + "' \"$@\"\n"
+	if err := os.WriteFile(filepath.Join(bin, "git"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	worktrees, err := listPorcelain(context.Background())
+	if err != nil {
+		t.Fatalf("listPorcelain() error = %v", err)
+	}
+	if len(worktrees) != 2 || worktrees[0].Path != dir || worktrees[1].Path != other || worktrees[1].Branch != "other" {
+		t.Errorf("listPorcelain() = %+v, want the worktrees at %s and %s", worktrees, dir, other)
+	}
+}
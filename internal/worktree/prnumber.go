@@ -0,0 +1,76 @@
+package worktree
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// builtinPRNumberPatterns are tried in order, most specific first. The first
+// submatch of each is the PR number.
+var builtinPRNumberPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`[-_]pr[-_/](\d+)`),   // web-frontend-pr-1018, fix_pr_12, x-pr/12
+	regexp.MustCompile(`^pr[-_/](\d+)`),      // pr-123, pr_123, pr/123
+	regexp.MustCompile(`[-_]pull[-_/](\d+)`), // x-pull-123, x_pull_123
+	regexp.MustCompile(`^pull[-_/](\d+)`),    // pull-123, pull_123, pull/123
+	regexp.MustCompile(`^(\d+)[-_]`),         // 123-feature, as add-pr names worktrees
+	trailingNumberRe,                         // feature-1234
+}
+
+// prNumberPatterns are the patterns PRNumber tries: those set with
+// SetPRNumberPatterns followed by the built-in ones.
+var prNumberPatterns = builtinPRNumberPatterns
+
+// trailingNumberRe matches a number at the end of a name. It needs 4+ digits
+// to avoid false positives such as step-2.
+var trailingNumberRe = regexp.MustCompile(`[-_](\d{4,})$`)
+
+// versionSuffixRe matches names ending in a version (bugfix-v1234) or a year
+// (release-2024), whose trailing number is not a PR number.
+var versionSuffixRe = regexp.MustCompile(`(?i)(v\d+|[-_](19|20)\d{2})$`)
+
+// SetPRNumberPatterns makes PRNumber try the given regular expressions, in
+// order, before the built-in conventions. The first capture group of a
+// pattern is the PR number, e.g. `/pr(\d+)$` for JIRA-123/pr456. Patterns set
+// before are dropped, so a repository never uses those of another one.
+func SetPRNumberPatterns(patterns []string) error {
+	var added []*regexp.Regexp
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return fmt.Errorf("invalid PR number pattern %q: %w", pattern, err)
+		}
+		if re.NumSubexp() < 1 {
+			return fmt.Errorf("invalid PR number pattern %q: it needs a capture group for the number", pattern)
+		}
+		added = append(added, re)
+	}
+	prNumberPatterns = append(added, builtinPRNumberPatterns...)
+	return nil
+}
+
+// PRNumber returns the PR number encoded in a branch or directory name by
+// the usual naming conventions, or 0 if there is none:
+//
+//	pr-123, pr_123, pr/123          PR prefix
+//	pull-123, pull_123, pull/123    pull prefix
+//	web-frontend-pr-1018            pr or pull segment after a dash or underscore
+//	123-feature                     leading number, as add-pr names worktrees
+//	feature-1234                    trailing number of at least 4 digits
+//
+// A trailing number that looks like a version (bugfix-v1234) or a year
+// (release-2024) is not taken as a PR number. Patterns set with
+// SetPRNumberPatterns are tried first.
+func PRNumber(name string) int {
+	for _, re := range prNumberPatterns {
+		if re == trailingNumberRe && versionSuffixRe.MatchString(name) {
+			continue
+		}
+		if matches := re.FindStringSubmatch(name); len(matches) > 1 {
+			if num, err := strconv.Atoi(matches[1]); err == nil {
+				return num
+			}
+		}
+	}
+	return 0
+}
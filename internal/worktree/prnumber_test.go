@@ -0,0 +1,123 @@
+package worktree
+
+import "testing"
+
+func TestPRNumberIgnoresVersionsAndYears(t *testing.T) {
+	tests := []struct {
+		name string
+		want int
+	}{
+		{"release-2024", 0},
+		{"release_1999", 0},
+		{"bugfix-v1234", 0},
+		{"feature-v1234", 0},
+		{"hotfix-V2048", 0},
+		{"web-frontend-pr-1018", 1018},
+		{"release-2024-pr-2031", 2031},
+		{"feature-2150", 2150},
+		{"feature-12024", 12024},
+	}
+	for _, tt := range tests {
+		if got := PRNumber(tt.name); got != tt.want {
+			t.Errorf("PRNumber(%q) = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestPRNumber(t *testing.T) {
+	tests := []struct {
+		name string
+		want int
+	}{
+		// PR prefix
+		{"pr-123", 123},
+		{"pr_123", 123},
+		{"pr/123", 123},
+		// pull prefix
+		{"pull-123", 123},
+		{"pull_123", 123},
+		{"pull/123", 123},
+		// pr or pull segment after a dash or underscore
+		{"web-frontend-pr-1018", 1018},
+		{"fix_pr_12", 12},
+		{"x-pr/12", 12},
+		{"x-pull-123", 123},
+		{"x_pull_123", 123},
+		// leading number, as add-pr names worktrees
+		{"123-feature", 123},
+		{"7_fix", 7},
+		// trailing number of at least 4 digits
+		{"feature-1234", 1234},
+		{"feature_1234", 1234},
+		// the most specific pattern wins
+		{"12-pr-34", 34},
+		// no PR number
+		{"", 0},
+		{"main", 0},
+		{"step-2", 0},
+		{"feature-123", 0},
+		{"april", 0},
+		{"sprint-42-cleanup", 0},
+		{"release-2024", 0},
+		{"bugfix-v1234", 0},
+	}
+	for _, tt := range tests {
+		if got := PRNumber(tt.name); got != tt.want {
+			t.Errorf("PRNumber(%q) = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSetPRNumberPatterns(t *testing.T) {
+	t.Cleanup(func() { _ = SetPRNumberPatterns(nil) })
+
+	if err := SetPRNumberPatterns([]string{`/pr(\d+)$`}); err != nil {
+		t.Fatalf("SetPRNumberPatterns() error = %v", err)
+	}
+	tests := []struct {
+		name string
+		want int
+	}{
+		{"JIRA-123/pr456", 456},
+		// The built-in conventions still apply
+		{"pr-789", 789},
+	}
+	for _, tt := range tests {
+		if got := PRNumber(tt.name); got != tt.want {
+			t.Errorf("PRNumber(%q) = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSetPRNumberPatternsReplacesEarlierOnes(t *testing.T) {
+	t.Cleanup(func() { _ = SetPRNumberPatterns(nil) })
+
+	for _, patterns := range [][]string{{`/pr(\d+)$`}, {`/review(\d+)$`}} {
+		if err := SetPRNumberPatterns(patterns); err != nil {
+			t.Fatalf("SetPRNumberPatterns(%q) error = %v", patterns, err)
+		}
+	}
+	if got := PRNumber("JIRA-123/pr456"); got != 0 {
+		t.Errorf("PRNumber(%q) = %d, want 0 after the pattern was replaced", "JIRA-123/pr456", got)
+	}
+	if got := PRNumber("JIRA-123/review456"); got != 456 {
+		t.Errorf("PRNumber(%q) = %d, want 456", "JIRA-123/review456", got)
+	}
+	if len(prNumberPatterns) != len(builtinPRNumberPatterns)+1 {
+		t.Errorf("%d patterns, want the built-in ones and one set", len(prNumberPatterns))
+	}
+}
+
+func TestSetPRNumberPatternsRejectsInvalid(t *testing.T) {
+	saved := prNumberPatterns
+	t.Cleanup(func() { prNumberPatterns = saved })
+
+	for _, pattern := range []string{`pr(\d+`, `pr\d+`} {
+		if err := SetPRNumberPatterns([]string{pattern}); err == nil {
+			t.Errorf("SetPRNumberPatterns(%q) error = nil, want an error", pattern)
+		}
+	}
+	if len(prNumberPatterns) != len(saved) {
+		t.Errorf("invalid patterns were added")
+	}
+}
@@ -1,109 +1,539 @@
 package worktree
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
 	"strings"
+	"time"
 
 	"github.com/cli/safeexec"
 )
 
-func Add(branch string, path string) error {
-	return AddWithOptions(branch, path, false)
+// Options configures how AddWithOptions creates a worktree.
+type Options struct {
+	// Path is where the worktree is created. When empty the worktree is
+	// placed in a directory named after the branch next to the main worktree.
+	Path string
+
+	// AppendBranch appends the branch name as a subdirectory of Path.
+	AppendBranch bool
+
+	// Name names the worktree directory instead of the branch. It is
+	// appended to Path, used instead of DefaultLayout, or replaces the last
+	// element of Layout. It must be a single directory name unless it
+	// starts with ./ or ../, which makes it a path relative to the
+	// directory the worktree would otherwise be created in.
+	Name string
+
+	// Slugify names the worktree directory after the slug of the branch
+	// (feature/x becomes feature-x) instead of nesting a directory per path
+	// segment. It applies to AppendBranch and to DefaultLayout.
+	Slugify bool
+
+	// Layout is a text/template for the worktree path, used when Path is
+	// empty. See RenderLayout for the available variables. Defaults to
+	// DefaultLayout.
+	Layout string
+
+	// LayoutVars are extra variables for Layout, such as repo, owner and pr.
+	LayoutVars map[string]interface{}
+
+	// BaseDir is the directory a relative Layout is resolved against.
+	// Environment variables and a leading ~ are expanded. Defaults to the
+	// directory containing the main worktree.
+	BaseDir string
+
+	// CopyPatterns are glob patterns of files, typically gitignored ones such
+	// as .env, to bring over from the current worktree into the new one.
+	// Patterns are resolved relative to the root of the current worktree.
+	CopyPatterns []string
+
+	// Symlink links the files matched by CopyPatterns instead of copying them.
+	Symlink bool
+
+	// CopyConfig are git config keys, such as core.hooksPath or user.email,
+	// whose values in the current worktree are set in the new one when it
+	// does not already see the same values.
+	CopyConfig []string
+
+	// Base is the ref a new branch is created from when the branch does not
+	// exist yet. Existing branches are checked out as they are.
+	Base string
+
+	// Fetch fetches the branch from origin and retries once when it cannot
+	// be found locally.
+	Fetch bool
+
+	// Detach creates the worktree with a detached HEAD at the given ref, a
+	// commit SHA, tag or branch, instead of checking out a branch. Base and
+	// Fetch don't apply.
+	Detach bool
+
+	// Progress receives messages about extra steps taken, such as fetching.
+	// It may be nil.
+	Progress io.Writer
+}
+
+// Add creates a worktree for branch and returns its path.
+func Add(ctx context.Context, branch string, path string) (string, error) {
+	return AddWithOptions(ctx, branch, Options{Path: path})
+}
+
+// AddPlan is what AddWithOptions does to create a worktree, as returned by
+// PlanAdd without doing any of it.
+type AddPlan struct {
+	// Path is the absolute path of the new worktree.
+	Path string
+
+	// GitArgs are the arguments of the git command creating the worktree.
+	GitArgs []string
+
+	// MayFetch is set when the branch does not exist locally, so it is
+	// fetched from origin if git can't find it either.
+	MayFetch bool
+
+	// Files are the files matched by Options.CopyPatterns.
+	Files []FileCopy
+
+	// Symlink links Files instead of copying them.
+	Symlink bool
+
+	// Config are the values of the keys in Options.CopyConfig that are set.
+	Config []ConfigValue
+}
+
+// FileCopy is a file or directory brought over into a new worktree.
+type FileCopy struct {
+	Source string
+	Target string
+}
+
+// AddWithOptions creates a worktree for branch as configured by opts and
+// returns its path. Errors match ErrWorktreeExists, ErrDirExists or
+// ErrBranchNotFound with errors.Is when those are the cause.
+func AddWithOptions(ctx context.Context, branch string, opts Options) (string, error) {
+	plan, err := PlanAdd(ctx, branch, opts)
+	if err != nil {
+		return "", err
+	}
+	branchPath := plan.Path
+
+	output, err := gitContext(ctx, plan.GitArgs)
+	if err != nil && plan.MayFetch && strings.Contains(err.Error(), "invalid reference") {
+		if opts.Progress != nil {
+			fmt.Fprintf(opts.Progress, "Branch '%s' not found locally, fetching origin/%s\n", branch, branch)
+		}
+		if fetchErr := Fetch(ctx, "origin", fmt.Sprintf("refs/heads/%s:refs/remotes/origin/%s", branch, branch)); fetchErr == nil {
+			output, err = gitContext(ctx, plan.GitArgs)
+		}
+	}
+	if err != nil {
+		// Parse git error for better messaging
+		if strings.Contains(err.Error(), "already exists") {
+			return "", &codedError{ErrWorktreeExists, fmt.Sprintf("worktree or branch '%s' already exists\nUse 'git worktree list' to see existing worktrees", branch)}
+		}
+		if strings.Contains(err.Error(), "invalid reference") {
+			return "", &codedError{ErrBranchNotFound, fmt.Sprintf("branch '%s' not found\nMake sure the branch exists or the PR has been fetched", branch)}
+		}
+		return "", fmt.Errorf("failed to create worktree: %w\nOutput: %s", err, string(output))
+	}
+
+	if err := copyFiles(plan.Files, plan.Symlink); err != nil {
+		return branchPath, fmt.Errorf("worktree created at %s but copying files failed: %w", branchPath, err)
+	}
+	if err := copyConfig(ctx, branchPath, plan.Config); err != nil {
+		return branchPath, fmt.Errorf("worktree created at %s but copying git config failed: %w", branchPath, err)
+	}
+	return branchPath, nil
 }
 
-func AddWithOptions(branch string, path string, appendBranch bool) error {
+// PlanAdd works out where and how AddWithOptions would create a worktree for
+// branch, running the same checks but changing nothing.
+func PlanAdd(ctx context.Context, branch string, opts Options) (AddPlan, error) {
+	if opts.Name != "" {
+		if err := checkName(opts.Name); err != nil {
+			return AddPlan{}, err
+		}
+	}
+
 	var branchPath string
-	if path != "" {
-		if appendBranch {
-			branchPath = filepath.Join(path, branch)
+	if opts.Path != "" {
+		if opts.Name != "" {
+			branchPath = filepath.Join(opts.Path, opts.Name)
+		} else if opts.AppendBranch && opts.Slugify {
+			branchPath = filepath.Join(opts.Path, Slug(branch))
+		} else if opts.AppendBranch {
+			branchPath = filepath.Join(opts.Path, branch)
 		} else {
-			branchPath = path
+			branchPath = opts.Path
 		}
 	} else {
-		gitPath, err := getCommonGitDirectory()
-		if err != nil {
-			return fmt.Errorf("could not get working directory: %w", err)
+		base := opts.BaseDir
+		if base != "" {
+			expanded, err := ExpandPath(base)
+			if err != nil {
+				return AddPlan{}, err
+			}
+			if expanded, err = AbsPath(expanded); err != nil {
+				return AddPlan{}, err
+			}
+			if err := checkWritable(expanded); err != nil {
+				return AddPlan{}, fmt.Errorf("cannot create worktrees in %s: %w", expanded, err)
+			}
+			base = expanded
+		} else {
+			gitPath, err := getCommonGitDirectory(ctx)
+			if err != nil {
+				return AddPlan{}, fmt.Errorf("could not get working directory: %w", err)
+			}
+			base = gitPath
 		}
 
-		branchPath = filepath.Join(gitPath, branch)
+		layout := opts.Layout
+		if layout == "" && opts.Slugify {
+			layout = slugLayout
+		} else if layout == "" {
+			layout = DefaultLayout
+		}
+		rendered, err := RenderLayout(layout, branch, opts.LayoutVars)
+		if err != nil {
+			return AddPlan{}, err
+		}
+		if opts.Name != "" && opts.Layout == "" {
+			rendered = opts.Name
+		} else if opts.Name != "" {
+			rendered = filepath.Join(filepath.Dir(rendered), opts.Name)
+		}
+		if filepath.IsAbs(rendered) {
+			branchPath = rendered
+		} else {
+			branchPath = filepath.Join(base, rendered)
+		}
+	}
+	if abs, err := AbsPath(branchPath); err == nil {
+		branchPath = abs
 	}
 
-	// Check if worktree already exists for this branch
-	existingPath, err := getWorktreePathForBranch(branch)
-	if err == nil && existingPath != "" {
-		return fmt.Errorf("worktree for branch '%s' already exists at: %s", branch, existingPath)
+	// Check if worktree already exists for this branch. A detached worktree
+	// checks out no branch, so any number of them can share a ref.
+	if opts.Detach {
+		if _, err := gitContext(ctx, []string{"rev-parse", "--verify", "--quiet", branch + "^{commit}"}); err != nil {
+			return AddPlan{}, &codedError{ErrBranchNotFound, fmt.Sprintf("ref '%s' not found\nMake sure the commit, tag or branch exists (git fetch --tags fetches missing tags)", branch)}
+		}
+	} else if existingPath, err := PathForBranch(ctx, branch); err == nil && existingPath != "" {
+		return AddPlan{}, &codedError{ErrWorktreeExists, fmt.Sprintf("worktree for branch '%s' already exists at: %s", branch, existingPath)}
 	}
 
 	// Check if the target directory already exists
 	if _, err := os.Stat(branchPath); err == nil {
-		return fmt.Errorf("directory already exists at: %s\nPlease remove it or choose a different path", branchPath)
+		return AddPlan{}, &codedError{ErrDirExists, fmt.Sprintf("directory already exists at: %s\nPlease remove it or choose a different path", branchPath)}
 	}
 
-	cmdArgs := []string{"worktree", "add", branchPath, branch}
+	plan := AddPlan{Path: branchPath, Symlink: opts.Symlink}
+	exists := BranchExists(ctx, branch)
+	plan.GitArgs = []string{"worktree", "add", branchPath, branch}
+	if opts.Detach {
+		plan.GitArgs = []string{"worktree", "add", "--detach", branchPath, branch}
+	} else if opts.Base != "" && !exists {
+		plan.GitArgs = []string{"worktree", "add", "-b", branch, branchPath, opts.Base}
+	} else {
+		plan.MayFetch = opts.Fetch && !exists
+	}
 
-	output, err := git(cmdArgs)
-	if err != nil {
-		// Parse git error for better messaging
-		if strings.Contains(err.Error(), "already exists") {
-			return fmt.Errorf("worktree or branch '%s' already exists\nUse 'git worktree list' to see existing worktrees", branch)
+	if len(opts.CopyPatterns) > 0 {
+		files, err := planCopy(ctx, branchPath, opts.CopyPatterns)
+		if err != nil {
+			return AddPlan{}, err
 		}
-		if strings.Contains(err.Error(), "invalid reference") {
-			return fmt.Errorf("branch '%s' not found\nMake sure the branch exists or the PR has been fetched", branch)
+		plan.Files = files
+	}
+	if len(opts.CopyConfig) > 0 {
+		config, err := planConfig(ctx, opts.CopyConfig)
+		if err != nil {
+			return AddPlan{}, err
 		}
-		return fmt.Errorf("failed to create worktree: %w\nOutput: %s", err, string(output))
+		plan.Config = config
 	}
-	return nil
+	return plan, nil
 }
 
-func getWorktreePathForBranch(branch string) (string, error) {
-	args := []string{"worktree", "list", "--porcelain"}
-	output, err := git(args)
+// PathForBranch returns the path of the worktree that has branch checked out,
+// or whose directory is named after branch. Detached worktrees are matched by
+// their HEAD commit when branch is a commit SHA (or a prefix of one). The
+// error matches ErrWorktreeNotFound when there is no such worktree.
+func PathForBranch(ctx context.Context, branch string) (string, error) {
+	worktrees, err := listPorcelain(ctx)
 	if err != nil {
 		return "", err
 	}
 
-	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
-	var currentPath string
-	for _, line := range lines {
-		if strings.HasPrefix(line, "worktree ") {
-			currentPath = strings.TrimPrefix(line, "worktree ")
+	for _, wt := range worktrees {
+		if wt.Detached && len(branch) >= 7 && strings.HasPrefix(wt.Head, branch) {
+			return wt.Path, nil
 		}
 		// Check both local branches and detached heads that might match the branch name
-		if strings.HasPrefix(line, "branch refs/heads/") {
-			currentBranch := strings.TrimPrefix(line, "branch refs/heads/")
-			if currentBranch == branch {
-				return currentPath, nil
-			}
+		if wt.Branch != "" && wt.Branch == branch {
+			return wt.Path, nil
 		}
 		// Also check if the path ends with the branch name (common pattern)
-		if currentPath != "" && filepath.Base(currentPath) == branch {
-			return currentPath, nil
+		if hasPathSuffix(wt.Path, branch) {
+			return wt.Path, nil
+		}
+	}
+	return "", &codedError{ErrWorktreeNotFound, fmt.Sprintf("worktree for branch %s not found", branch)}
+}
+
+// hasPathSuffix reports whether the last segments of path are the slash
+// separated segments of suffix, e.g. /src/feat/x ends with feat/x.
+func hasPathSuffix(path string, suffix string) bool {
+	suffix = NormalizePath(suffix)
+	if suffix == "" || suffix == "." {
+		return false
+	}
+	return path == suffix || strings.HasSuffix(path, string(filepath.Separator)+suffix)
+}
+
+// CheckedOutBranches returns the branches checked out in any worktree.
+func CheckedOutBranches(ctx context.Context) ([]string, error) {
+	worktrees, err := listPorcelain(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var branches []string
+	for _, wt := range worktrees {
+		if wt.Branch != "" {
+			branches = append(branches, wt.Branch)
+		}
+	}
+	return branches, nil
+}
+
+// AllBranches returns the names of all local and remote-tracking branches,
+// with the remote name stripped from remote branches and duplicates removed.
+func AllBranches(ctx context.Context) ([]string, error) {
+	output, err := gitContext(ctx, []string{"for-each-ref", "--format=%(refname)", "refs/heads", "refs/remotes"})
+	if err != nil {
+		return nil, err
+	}
+
+	var branches []string
+	seen := map[string]bool{}
+	for _, ref := range strings.Fields(string(output)) {
+		var name string
+		if strings.HasPrefix(ref, "refs/heads/") {
+			name = strings.TrimPrefix(ref, "refs/heads/")
+		} else {
+			// refs/remotes/<remote>/<branch>
+			parts := strings.SplitN(strings.TrimPrefix(ref, "refs/remotes/"), "/", 2)
+			if len(parts) < 2 || parts[1] == "HEAD" {
+				continue
+			}
+			name = parts[1]
+		}
+		if !seen[name] {
+			seen[name] = true
+			branches = append(branches, name)
+		}
+	}
+	return branches, nil
+}
+
+// checkWritable reports an error unless files can be created in dir, or in its
+// nearest existing parent when dir does not exist yet.
+func checkWritable(dir string) error {
+	for {
+		info, err := os.Stat(dir)
+		if err == nil {
+			if !info.IsDir() {
+				return fmt.Errorf("%s is not a directory", dir)
+			}
+			break
+		}
+		if !os.IsNotExist(err) {
+			return err
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return err
 		}
+		dir = parent
+	}
+
+	f, err := os.CreateTemp(dir, ".gh-worktree-*")
+	if err != nil {
+		return fmt.Errorf("directory is not writable: %w", err)
+	}
+	f.Close()
+	return os.Remove(f.Name())
+}
+
+// DefaultBaseDir returns the directory new worktrees are created in when no
+// path is given.
+func DefaultBaseDir(ctx context.Context) (string, error) {
+	return getCommonGitDirectory(ctx)
+}
+
+// RepoRoot returns the root directory of the repository, i.e. the directory
+// containing the common git directory, or the bare repository itself.
+func RepoRoot(ctx context.Context) (string, error) {
+	return getCommonGitDirectory(ctx)
+}
+
+// BranchExists reports whether a local branch with the given name exists.
+func BranchExists(ctx context.Context, branch string) bool {
+	_, err := gitContext(ctx, []string{"show-ref", "--verify", "--quiet", "refs/heads/" + branch})
+	return err == nil
+}
+
+// Fetch fetches refspec from remote.
+func Fetch(ctx context.Context, remote string, refspec string) error {
+	output, err := gitContext(ctx, []string{"fetch", remote, refspec})
+	if err != nil {
+		return fmt.Errorf("failed to fetch %s from %s: %w\nOutput: %s", refspec, remote, err, string(output))
+	}
+	return nil
+}
+
+// Slug turns a branch name into a single path segment by replacing path
+// separators and other unsafe characters with dashes.
+func Slug(branch string) string {
+	return slugRe.ReplaceAllString(branch, "-")
+}
+
+// checkName returns an error when name, as given in Options.Name, is not a
+// single directory name and does not start with ./ or ../ either.
+func checkName(name string) error {
+	slashed := strings.ReplaceAll(name, `\`, "/")
+	clean := filepath.Clean(name)
+	switch {
+	case clean == "." || clean == "..":
+		return fmt.Errorf("invalid worktree name %q", name)
+	case strings.HasPrefix(slashed, "./") || strings.HasPrefix(slashed, "../"):
+		return nil
+	case strings.Contains(slashed, "/"):
+		return fmt.Errorf("invalid worktree name %q: use a single directory name, or a relative path starting with ./ or ../", name)
 	}
-	return "", fmt.Errorf("worktree for branch %s not found", branch)
+	return nil
 }
 
-func getCommonGitDirectory() (string, error) {
-	args := []string{"rev-parse", "--git-common-dir"}
-	b, err := git(args)
+var slugRe = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// getCommonGitDirectory returns the directory worktrees are created in by
+// default: the parent of the common git directory, e.g. of .git. A bare
+// repository such as repo.git has no working tree around it, so worktrees go
+// inside it instead. A bare repository in a hidden directory, as in the
+// common layout of a .bare directory next to a .git file pointing at it, is
+// treated like .git.
+func getCommonGitDirectory(ctx context.Context) (string, error) {
+	commonDir, err := gitCommonDir(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	bare, err := isBareRepository(ctx, commonDir)
+	if err != nil {
+		return "", err
+	}
+	if bare && !strings.HasPrefix(filepath.Base(commonDir), ".") {
+		return commonDir, nil
+	}
+	return filepath.Dir(commonDir), nil
+}
+
+// gitCommonDir returns the absolute path of the git directory shared by all
+// worktrees, e.g. the .git directory of the main worktree.
+func gitCommonDir(ctx context.Context) (string, error) {
+	b, err := gitContext(ctx, []string{"rev-parse", "--git-common-dir"})
+	if err != nil {
+		return "", fmt.Errorf("could not get git common dir: %w", err)
+	}
+
+	commonDir, err := AbsPath(strings.TrimSpace(string(b)))
 	if err != nil {
 		return "", fmt.Errorf("could not get git common dir: %w", err)
 	}
+	return commonDir, nil
+}
+
+// isBareRepository reports whether the repository with the given common git
+// directory is bare. Asking from inside the common directory gives the same
+// answer in the main and in linked worktrees.
+func isBareRepository(ctx context.Context, commonDir string) (bool, error) {
+	b, err := gitContext(ctx, []string{"-C", commonDir, "rev-parse", "--is-bare-repository"})
+	if err != nil {
+		return false, fmt.Errorf("could not check for a bare repository: %w", err)
+	}
+	return strings.TrimSpace(string(b)) == "true", nil
+}
 
-	root := filepath.Join(string(b), "..")
+// Dir is the directory git commands run in, set by the global -C flag.
+// Relative paths are resolved against it as well. Empty means the working
+// directory of the process.
+var Dir string
 
-	return root, nil
+// AbsPath returns path made absolute against Dir.
+func AbsPath(path string) (string, error) {
+	if filepath.IsAbs(path) {
+		return filepath.Clean(path), nil
+	}
+	if Dir == "" {
+		return filepath.Abs(path)
+	}
+	return filepath.Abs(filepath.Join(Dir, path))
 }
 
-func git(args []string) ([]byte, error) {
+// Trace, when set, is called with the arguments of every git command before
+// it is run.
+var Trace func(args []string)
+
+// GitTimeout is how long a single git command may run before it is killed.
+// Zero disables the timeout.
+var GitTimeout = 30 * time.Second
+
+// WithGitTimeout returns a context that is done after GitTimeout, or ctx
+// itself with a no-op cancel func when the timeout is disabled.
+func WithGitTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if GitTimeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, GitTimeout)
+}
+
+// TimeoutError returns the error for a git command with args that was killed
+// after GitTimeout.
+func TimeoutError(args []string) error {
+	return fmt.Errorf("git %s timed out after %s (raise --git-timeout, or set it to 0 to disable the timeout)", strings.Join(args, " "), GitTimeout)
+}
+
+// gitContext runs git with args, killing it when ctx is done or it runs
+// longer than GitTimeout.
+func gitContext(ctx context.Context, args []string) ([]byte, error) {
 	cmd, err := safeexec.LookPath("git")
 	if err != nil {
 		return nil, err
 	}
-	c := exec.Command(cmd, args...)
+	if Trace != nil {
+		Trace(args)
+	}
+	timeoutCtx, cancel := WithGitTimeout(ctx)
+	defer cancel()
+	c := exec.CommandContext(timeoutCtx, cmd, args...)
+	c.Dir = Dir
 
-	return c.Output()
+	output, err := c.Output()
+	if err != nil && ctx.Err() == nil && errors.Is(timeoutCtx.Err(), context.DeadlineExceeded) {
+		return output, TimeoutError(args)
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		err = fmt.Errorf("%w: %s", err, strings.TrimSpace(string(exitErr.Stderr)))
+	}
+	return output, err
 }
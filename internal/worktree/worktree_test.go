@@ -0,0 +1,272 @@
+package worktree
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPathForBranchDetached(t *testing.T) {
+	dir := newTestRepo(t, "main")
+	review := filepath.Join(filepath.Dir(dir), "pr-77-review")
+	runGit(t, dir, "worktree", "add", "-q", "--detach", review)
+	head := runGit(t, review, "rev-parse", "HEAD")
+
+	tests := []struct {
+		target string
+		want   string
+	}{
+		// The main worktree is on the same commit but not detached
+		{head, review},
+		{head[:7], review},
+		{"pr-77-review", review},
+		{"main", dir},
+	}
+	for _, tt := range tests {
+		got, err := PathForBranch(context.Background(), tt.target)
+		if err != nil {
+			t.Errorf("PathForBranch(context.Background(), %q) error = %v", tt.target, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("PathForBranch(context.Background(), %q) = %s, want %s", tt.target, got, tt.want)
+		}
+	}
+
+	// Too short to be taken for a commit
+	if _, err := PathForBranch(context.Background(), head[:6]); !errors.Is(err, ErrWorktreeNotFound) {
+		t.Errorf("PathForBranch(context.Background(), %q) error = %v, want ErrWorktreeNotFound", head[:6], err)
+	}
+}
+
+func TestSlug(t *testing.T) {
+	tests := []struct {
+		branch string
+		want   string
+	}{
+		{"feature/sub/thing", "feature-sub-thing"},
+		{"feature/foo", "feature-foo"},
+		{"plain", "plain"},
+		{"fix//double", "fix-double"},
+		{"user name/x", "user-name-x"},
+		{"v1.2_rc", "v1.2_rc"},
+	}
+	for _, tt := range tests {
+		if got := Slug(tt.branch); got != tt.want {
+			t.Errorf("Slug(%q) = %q, want %q", tt.branch, got, tt.want)
+		}
+	}
+}
+
+func TestPlanAddSlugify(t *testing.T) {
+	dir := newTestRepo(t, "main")
+	runGit(t, dir, "branch", "feature/sub/thing")
+	custom := filepath.Join(filepath.Dir(dir), "wt")
+
+	tests := []struct {
+		name string
+		opts Options
+		want string
+	}{
+		{"default nests a directory per segment", Options{}, filepath.Join(dir, "feature", "sub", "thing")},
+		{"slugify", Options{Slugify: true}, filepath.Join(dir, "feature-sub-thing")},
+		{"append branch", Options{Path: custom, AppendBranch: true}, filepath.Join(custom, "feature", "sub", "thing")},
+		{"append slugified branch", Options{Path: custom, AppendBranch: true, Slugify: true}, filepath.Join(custom, "feature-sub-thing")},
+		{"custom layout ignores slugify", Options{Layout: "wt/{{.branch}}", Slugify: true}, filepath.Join(dir, "wt", "feature", "sub", "thing")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan, err := PlanAdd(context.Background(), "feature/sub/thing", tt.opts)
+			if err != nil {
+				t.Fatalf("PlanAdd(context.Background()) error = %v", err)
+			}
+			if plan.Path != tt.want {
+				t.Errorf("PlanAdd(context.Background()) path = %s, want %s", plan.Path, tt.want)
+			}
+			// The real branch is checked out whatever the directory is named
+			if args := plan.GitArgs; args[len(args)-1] != "feature/sub/thing" {
+				t.Errorf("PlanAdd(context.Background()) git args = %v, want the branch feature/sub/thing checked out", args)
+			}
+		})
+	}
+}
+
+func TestAddWithOptionsSlugify(t *testing.T) {
+	dir := newTestRepo(t, "main")
+	runGit(t, dir, "branch", "feature/sub/thing")
+
+	path, err := AddWithOptions(context.Background(), "feature/sub/thing", Options{Slugify: true})
+	if err != nil {
+		t.Fatalf("AddWithOptions(context.Background()) error = %v", err)
+	}
+	if want := filepath.Join(dir, "feature-sub-thing"); path != want {
+		t.Errorf("AddWithOptions(context.Background()) = %s, want %s", path, want)
+	}
+	if branch := runGit(t, path, "branch", "--show-current"); branch != "feature/sub/thing" {
+		t.Errorf("worktree has %q checked out, want feature/sub/thing", branch)
+	}
+}
+
+func TestDefaultBaseDirBare(t *testing.T) {
+	src := newTestRepo(t, "main")
+	root := filepath.Dir(src)
+
+	// repo.git: a bare clone, with worktrees inside it
+	bare := filepath.Join(root, "repo.git")
+	runGit(t, root, "clone", "-q", "--bare", src, bare)
+	inBare := filepath.Join(bare, "feature")
+	runGit(t, bare, "worktree", "add", "-q", "-b", "feature", inBare)
+
+	// proj/.bare next to a .git file pointing at it, with worktrees next to it
+	proj := filepath.Join(root, "proj")
+	hidden := filepath.Join(proj, ".bare")
+	runGit(t, root, "clone", "-q", "--bare", src, hidden)
+	if err := os.WriteFile(filepath.Join(proj, ".git"), []byte("gitdir: ./.bare\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	inProj := filepath.Join(proj, "feature")
+	runGit(t, proj, "worktree", "add", "-q", "-b", "feature", inProj)
+
+	tests := []struct {
+		name string
+		dir  string
+		want string
+	}{
+		{"regular repository", src, src},
+		{"bare repository", bare, bare},
+		{"worktree of a bare repository", inBare, bare},
+		{"hidden bare repository", proj, proj},
+		{"worktree of a hidden bare repository", inProj, proj},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			Dir = tt.dir
+			got, err := DefaultBaseDir(context.Background())
+			if err != nil {
+				t.Fatalf("DefaultBaseDir(context.Background()) error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("DefaultBaseDir(context.Background()) in %s = %s, want %s", tt.dir, got, tt.want)
+			}
+		})
+	}
+
+	Dir = inBare
+	plan, err := PlanAdd(context.Background(), "other", Options{Base: "main"})
+	if err != nil {
+		t.Fatalf("PlanAdd(context.Background()) error = %v", err)
+	}
+	if want := filepath.Join(bare, "other"); plan.Path != want {
+		t.Errorf("PlanAdd(context.Background()) in a bare repository = %s, want %s", plan.Path, want)
+	}
+}
+
+func TestHasPathSuffix(t *testing.T) {
+	tests := []struct {
+		path   string
+		suffix string
+		want   bool
+	}{
+		{"/src/feat/x", "feat/x", true},
+		{"/src/feat/x", "feat/x/", true},
+		{"/src/feat/x", "./feat/x", true},
+		{"/src/feat/x", "x", true},
+		{"/src/feat/x", "src/feat/x", true},
+		{"/src/afeat/x", "feat/x", false},
+		{"/src/feat/xy", "feat/x", false},
+		{"/src/feat/x", "", false},
+		{"/src/feat/x", ".", false},
+		{"/src/feat/x", "/", false},
+	}
+	for _, tt := range tests {
+		path := filepath.FromSlash(tt.path)
+		if got := hasPathSuffix(path, tt.suffix); got != tt.want {
+			t.Errorf("hasPathSuffix(%q, %q) = %v, want %v", path, tt.suffix, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizePath(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"", ""},
+		{"/src/app/", "/src/app"},
+		{"/src//app/./feat/../x", "/src/app/x"},
+		// git prints forward slashes on every OS
+		{"C:/src/app", "C:/src/app"},
+	}
+	for _, tt := range tests {
+		if got, want := NormalizePath(tt.path), filepath.FromSlash(tt.want); got != want {
+			t.Errorf("NormalizePath(%q) = %q, want %q", tt.path, got, want)
+		}
+	}
+}
+
+func TestSamePathSymlink(t *testing.T) {
+	root := evalSymlinks(t, t.TempDir())
+	real := filepath.Join(root, "real")
+	link := filepath.Join(root, "link")
+	if err := os.Mkdir(real, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(real, link); err != nil {
+		t.Skipf("symlinks are not supported: %v", err)
+	}
+
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{real, real + string(filepath.Separator), true},
+		{link, real, true},
+		{filepath.ToSlash(link) + "/", real, true},
+		{link, root, false},
+	}
+	for _, tt := range tests {
+		if got := SamePath(tt.a, tt.b); got != tt.want {
+			t.Errorf("SamePath(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestPathForBranchPaths(t *testing.T) {
+	dir := newTestRepo(t, "main")
+	root := filepath.Dir(dir)
+	nested := filepath.Join(root, "sub", "feat", "two")
+	runGit(t, dir, "worktree", "add", "-q", "-b", "other", nested)
+
+	// A worktree added through a symlinked directory is still found
+	if err := os.Symlink(root, filepath.Join(root, "link")); err != nil {
+		t.Skipf("symlinks are not supported: %v", err)
+	}
+	viaLink := filepath.Join(root, "link", "linked")
+	runGit(t, dir, "worktree", "add", "-q", "-b", "linked", viaLink)
+
+	tests := []struct {
+		target string
+		want   string
+	}{
+		{"other", nested},
+		{"feat/two", nested},
+		{"feat/two/", nested},
+		{"sub/feat/two", nested},
+		{"linked", filepath.Join(root, "linked")},
+	}
+	for _, tt := range tests {
+		got, err := PathForBranch(context.Background(), tt.target)
+		if err != nil {
+			t.Errorf("PathForBranch(context.Background(), %q) error = %v", tt.target, err)
+			continue
+		}
+		if !SamePath(got, tt.want) {
+			t.Errorf("PathForBranch(context.Background(), %q) = %s, want %s", tt.target, got, tt.want)
+		}
+	}
+	if _, err := PathForBranch(context.Background(), "eat/two"); !errors.Is(err, ErrWorktreeNotFound) {
+		t.Errorf("PathForBranch(context.Background(), %q) error = %v, want ErrWorktreeNotFound", "eat/two", err)
+	}
+}
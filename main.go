@@ -1,22 +1,39 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"os/signal"
 
 	"github.com/eikster-dk/gh-worktree/internal/cli"
 )
 
 func main() {
 	if err := run(); err != nil {
-		fmt.Println(err.Error())
+		var exitErr cli.ExitError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.Code)
+		}
+		fmt.Fprintln(os.Stderr, err.Error())
 		os.Exit(1)
 	}
 }
 
 func run() error {
+	// Cancel in-flight git and GitHub calls on the first interrupt. Once
+	// cancelled, the default handler is restored so a second interrupt exits
+	// immediately.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
 	root := cli.NewRoot()
-	_, err := root.ExecuteC()
+	_, err := root.ExecuteContextC(ctx)
 
 	return err
 }